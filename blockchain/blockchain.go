@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
@@ -27,6 +28,10 @@ func (e ErrIncompatibleBlockAndStateUpdate) Unwrap() error {
 	return e.Err
 }
 
+// ErrParentDoesNotMatchHead is wrapped in [ErrIncompatibleBlock] when a block does not
+// extend the current head, which is the case after a chain reorganisation.
+var ErrParentDoesNotMatchHead = errors.New("block's parent hash does not match head block hash")
+
 type ErrIncompatibleBlock struct {
 	Err error
 }
@@ -44,13 +49,29 @@ type Reader interface {
 	Head() (head *core.Block, err error)
 	GetBlockByNumber(number uint64) (block *core.Block, err error)
 	GetBlockByHash(hash *felt.Felt) (block *core.Block, err error)
+	GetBlockHeaderByHash(hash *felt.Felt) (header *core.Header, err error)
 	GetTransactionByHash(hash *felt.Felt) (transaction core.Transaction, err error)
+	GetReceipt(hash *felt.Felt) (receipt *core.TransactionReceipt, blockHash *felt.Felt, blockNumber uint64, err error)
+	GetStateUpdateByNumber(number uint64) (update *core.StateUpdate, err error)
+	GetStateUpdateByHash(hash *felt.Felt) (update *core.StateUpdate, err error)
+	ContractStorageAt(addr, key *felt.Felt, blockNumber uint64) (value *felt.Felt, err error)
+	ContractNonceAt(addr *felt.Felt, blockNumber uint64) (nonce *felt.Felt, err error)
+	ContractClassHashAt(addr *felt.Felt, blockNumber uint64) (classHash *felt.Felt, err error)
+	GetClass(classHash *felt.Felt) (class *core.Class, err error)
+	ClassAt(classHash *felt.Felt, blockNumber uint64) (class *core.Class, err error)
+	Events(filter *EventFilter, start EventPosition, chunkSize, maxBlocks uint64) (events []*FilteredEvent,
+		next *EventPosition, err error)
+	Pending() (pending *Pending, err error)
+	L1Head() (head *core.L1Head, err error)
 }
 
 // Blockchain is responsible for keeping track of all things related to the Starknet blockchain
 type Blockchain struct {
 	network  utils.Network
 	database db.DB
+
+	pendingLock sync.RWMutex
+	pending     *Pending
 }
 
 func New(database db.DB, network utils.Network) *Blockchain {
@@ -117,6 +138,15 @@ func (b *Blockchain) GetBlockByHash(hash *felt.Felt) (block *core.Block, err err
 	})
 }
 
+// GetBlockHeaderByHash returns the header of the block with the given hash without
+// loading its transactions and receipts.
+func (b *Blockchain) GetBlockHeaderByHash(hash *felt.Felt) (header *core.Header, err error) {
+	return header, b.database.View(func(txn db.Transaction) error {
+		header, err = getBlockHeaderByHash(txn, hash)
+		return err
+	})
+}
+
 func (b *Blockchain) GetStateUpdateByNumber(number uint64) (update *core.StateUpdate, err error) {
 	return update, b.database.View(func(txn db.Transaction) error {
 		update, err = getStateUpdateByNumber(txn, number)
@@ -131,6 +161,57 @@ func (b *Blockchain) GetStateUpdateByHash(hash *felt.Felt) (update *core.StateUp
 	})
 }
 
+// ContractStorageAt returns the value of a contract's storage slot right after the block
+// with the given number was applied.
+func (b *Blockchain) ContractStorageAt(addr, key *felt.Felt, blockNumber uint64) (value *felt.Felt, err error) {
+	return value, b.database.View(func(txn db.Transaction) error {
+		value, err = core.NewHistoricalState(txn, blockNumber).ContractStorage(addr, key)
+		return err
+	})
+}
+
+// ContractNonceAt returns the nonce of a contract right after the block with the given number was applied.
+func (b *Blockchain) ContractNonceAt(addr *felt.Felt, blockNumber uint64) (nonce *felt.Felt, err error) {
+	return nonce, b.database.View(func(txn db.Transaction) error {
+		nonce, err = core.NewHistoricalState(txn, blockNumber).ContractNonce(addr)
+		return err
+	})
+}
+
+// ContractClassHashAt returns the class hash of a contract right after the block with the given number
+// was applied.
+func (b *Blockchain) ContractClassHashAt(addr *felt.Felt, blockNumber uint64) (classHash *felt.Felt, err error) {
+	return classHash, b.database.View(func(txn db.Transaction) error {
+		classHash, err = core.NewHistoricalState(txn, blockNumber).ContractClassHash(addr)
+		return err
+	})
+}
+
+// GetClass gets the class for a given class hash.
+func (b *Blockchain) GetClass(classHash *felt.Felt) (class *core.Class, err error) {
+	return class, b.database.View(func(txn db.Transaction) error {
+		class, err = core.NewState(txn).GetClass(classHash)
+		return err
+	})
+}
+
+// ClassAt returns the class with the given class hash if it was declared at or before the
+// block with the given number.
+func (b *Blockchain) ClassAt(classHash *felt.Felt, blockNumber uint64) (class *core.Class, err error) {
+	return class, b.database.View(func(txn db.Transaction) error {
+		class, err = core.NewHistoricalState(txn, blockNumber).Class(classHash)
+		return err
+	})
+}
+
+// HasClass reports whether the class with the given class hash is stored.
+func (b *Blockchain) HasClass(classHash *felt.Felt) (stored bool, err error) {
+	return stored, b.database.View(func(txn db.Transaction) error {
+		stored, err = core.NewState(txn).HasClass(classHash)
+		return err
+	})
+}
+
 // GetTransactionByBlockNumberAndIndex gets the transaction for a given block number and index.
 func (b *Blockchain) GetTransactionByBlockNumberAndIndex(blockNumber, index uint64) (transaction core.Transaction, err error) {
 	return transaction, b.database.View(func(txn db.Transaction) error {
@@ -147,11 +228,28 @@ func (b *Blockchain) GetTransactionByHash(hash *felt.Felt) (transaction core.Tra
 	})
 }
 
-// GetReceipt gets the transaction receipt for a given transaction hash.
-func (b *Blockchain) GetReceipt(hash *felt.Felt) (receipt *core.TransactionReceipt, err error) {
-	return receipt, b.database.View(func(txn db.Transaction) error {
-		receipt, err = getReceiptByHash(txn, hash)
-		return err
+// GetReceipt gets the transaction receipt for a given transaction hash, along with the hash and
+// number of the block that includes the transaction.
+func (b *Blockchain) GetReceipt(hash *felt.Felt) (receipt *core.TransactionReceipt, blockHash *felt.Felt,
+	blockNumber uint64, err error,
+) {
+	return receipt, blockHash, blockNumber, b.database.View(func(txn db.Transaction) error {
+		bnIndex, err := getTransactionBlockNumberAndIndexByHash(txn, hash)
+		if err != nil {
+			return err
+		}
+
+		header, err := getBlockHeaderByNumber(txn, bnIndex.Number)
+		if err != nil {
+			return err
+		}
+
+		receipt, err = getReceiptByBlockNumberAndIndex(txn, bnIndex)
+		if err != nil {
+			return err
+		}
+		blockHash, blockNumber = header.Hash, header.Number
+		return nil
 	})
 }
 
@@ -161,7 +259,7 @@ func (b *Blockchain) Store(block *core.Block, stateUpdate *core.StateUpdate, dec
 		if err := b.verifyBlock(txn, block); err != nil {
 			return err
 		}
-		if err := core.NewState(txn).Update(stateUpdate, declaredClasses); err != nil {
+		if err := core.NewState(txn).Update(block.Number, stateUpdate, declaredClasses); err != nil {
 			return err
 		}
 		if err := storeBlockHeader(txn, &block.Header); err != nil {
@@ -174,6 +272,10 @@ func (b *Blockchain) Store(block *core.Block, stateUpdate *core.StateUpdate, dec
 			}
 		}
 
+		if err := storeEventFilter(txn, block.Number, block.Receipts); err != nil {
+			return err
+		}
+
 		if err := storeStateUpdate(txn, block.Number, stateUpdate); err != nil {
 			return err
 		}
@@ -186,6 +288,106 @@ func (b *Blockchain) Store(block *core.Block, stateUpdate *core.StateUpdate, dec
 	})
 }
 
+// RevertHead removes the head block from the chain: its header, transactions, receipts,
+// event filter and state update are deleted and its state changes are undone.
+func (b *Blockchain) RevertHead() error {
+	return b.database.Update(func(txn db.Transaction) error {
+		height, err := b.height(txn)
+		if err != nil {
+			return err
+		}
+		header, err := getBlockHeaderByNumber(txn, height)
+		if err != nil {
+			return err
+		}
+		stateUpdate, err := getStateUpdateByNumber(txn, height)
+		if err != nil {
+			return err
+		}
+		if err = core.NewState(txn).Revert(height, stateUpdate); err != nil {
+			return err
+		}
+
+		receipts, err := getReceiptsByBlockNumber(txn, height)
+		if err != nil {
+			return err
+		}
+		for i, receipt := range receipts {
+			if err = deleteTransactionAndReceipt(txn, height, uint64(i), receipt); err != nil {
+				return err
+			}
+		}
+
+		numBytes := make([]byte, lenOfByteSlice)
+		binary.BigEndian.PutUint64(numBytes, height)
+		for _, key := range [][]byte{
+			db.BlockHeaderNumbersByHash.Key(header.Hash.Marshal()),
+			db.BlockHeadersByNumber.Key(numBytes),
+			db.EventFiltersByBlockNumber.Key(numBytes),
+			db.StateUpdatesByBlockNumber.Key(numBytes),
+		} {
+			if err = txn.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		if height == 0 {
+			return txn.Delete(db.ChainHeight.Key())
+		}
+		heightBin := make([]byte, lenOfByteSlice)
+		binary.BigEndian.PutUint64(heightBin, height-1)
+		return txn.Set(db.ChainHeight.Key(), heightBin)
+	})
+}
+
+// SetL1Head records the latest Starknet block accepted on L1. The db storage for the L1 head
+// is maintained as follows:
+//
+// [db.L1Head]() -> (L1Head)
+func (b *Blockchain) SetL1Head(head *core.L1Head) error {
+	headBytes, err := encoder.Marshal(head)
+	if err != nil {
+		return err
+	}
+	return b.database.Update(func(txn db.Transaction) error {
+		return txn.Set(db.L1Head.Key(), headBytes)
+	})
+}
+
+// L1Head returns the latest Starknet block accepted on L1. If none has been recorded yet
+// [db.ErrKeyNotFound] is returned.
+func (b *Blockchain) L1Head() (head *core.L1Head, err error) {
+	return head, b.database.View(func(txn db.Transaction) error {
+		return txn.Get(db.L1Head.Key(), func(val []byte) error {
+			head = new(core.L1Head)
+			return encoder.Unmarshal(val, head)
+		})
+	})
+}
+
+// SetL1ScannedHeight records the latest L1 block whose logs have been processed. The db
+// storage for it is maintained as follows:
+//
+// [db.L1ScannedHeight]() -> (L1BlockNumber)
+func (b *Blockchain) SetL1ScannedHeight(l1BlockNumber uint64) error {
+	heightBin := make([]byte, lenOfByteSlice)
+	binary.BigEndian.PutUint64(heightBin, l1BlockNumber)
+	return b.database.Update(func(txn db.Transaction) error {
+		return txn.Set(db.L1ScannedHeight.Key(), heightBin)
+	})
+}
+
+// L1ScannedHeight returns the latest L1 block whose logs have been processed. If none has
+// been recorded yet [db.ErrKeyNotFound] is returned.
+func (b *Blockchain) L1ScannedHeight() (height uint64, err error) {
+	return height, b.database.View(func(txn db.Transaction) error {
+		return txn.Get(db.L1ScannedHeight.Key(), func(val []byte) error {
+			height = binary.BigEndian.Uint64(val)
+			return nil
+		})
+	})
+}
+
 // VerifyBlock assumes the block has already been sanity-checked.
 func (b *Blockchain) VerifyBlock(block *core.Block) error {
 	return b.database.View(func(txn db.Transaction) error {
@@ -216,9 +418,7 @@ func (b *Blockchain) verifyBlock(txn db.Transaction, block *core.Block) error {
 			}
 		}
 		if !block.ParentHash.Equal(head.Hash) {
-			return ErrIncompatibleBlock{
-				errors.New("block's parent hash does not match head block hash"),
-			}
+			return ErrIncompatibleBlock{ErrParentDoesNotMatchHead}
 		}
 	}
 
@@ -251,17 +451,27 @@ func storeBlockHeader(txn db.Transaction, block *core.Header) error {
 	return nil
 }
 
+// getBlockHeaderByNumber retrieves a block header from database by its number
+func getBlockHeaderByNumber(txn db.Transaction, number uint64) (header *core.Header, err error) {
+	numBytes := make([]byte, lenOfByteSlice)
+	binary.BigEndian.PutUint64(numBytes, number)
+
+	return header, txn.Get(db.BlockHeadersByNumber.Key(numBytes), func(val []byte) error {
+		header = new(core.Header)
+		return encoder.Unmarshal(val, header)
+	})
+}
+
 // getBlockByNumber retrieves a block from database by its number
 func getBlockByNumber(txn db.Transaction, number uint64) (block *core.Block, err error) {
 	numBytes := make([]byte, lenOfByteSlice)
 	binary.BigEndian.PutUint64(numBytes, number)
 
-	block = new(core.Block)
-	if err = txn.Get(db.BlockHeadersByNumber.Key(numBytes), func(val []byte) error {
-		return encoder.Unmarshal(val, &block.Header)
-	}); err != nil {
+	header, err := getBlockHeaderByNumber(txn, number)
+	if err != nil {
 		return nil, err
 	}
+	block = &core.Block{Header: *header}
 
 	iterator, err := txn.NewIterator()
 	if err != nil {
@@ -293,7 +503,29 @@ func getBlockByNumber(txn db.Transaction, number uint64) (block *core.Block, err
 		block.Transactions = append(block.Transactions, tx)
 	}
 
-	prefix = db.ReceiptsByBlockNumberAndIndex.Key(numBytes)
+	if block.Receipts, err = getReceiptsByBlockNumber(txn, number); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// getReceiptsByBlockNumber retrieves all transaction receipts of a block from database by its number
+func getReceiptsByBlockNumber(txn db.Transaction, number uint64) (receipts []*core.TransactionReceipt, err error) {
+	numBytes := make([]byte, lenOfByteSlice)
+	binary.BigEndian.PutUint64(numBytes, number)
+
+	iterator, err := txn.NewIterator()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Prioritise closing error over other errors
+		if closeErr := iterator.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+
+	prefix := db.ReceiptsByBlockNumberAndIndex.Key(numBytes)
 	for iterator.Seek(prefix); iterator.Valid(); iterator.Next() {
 		if !bytes.Equal(iterator.Key()[:len(prefix)], prefix) {
 			break
@@ -309,10 +541,10 @@ func getBlockByNumber(txn db.Transaction, number uint64) (block *core.Block, err
 			return nil, err
 		}
 
-		block.Receipts = append(block.Receipts, receipt)
+		receipts = append(receipts, receipt)
 	}
 
-	return block, nil
+	return receipts, nil
 }
 
 // getBlockByHash retrieves a block from database by its hash
@@ -323,6 +555,14 @@ func getBlockByHash(txn db.Transaction, hash *felt.Felt) (block *core.Block, err
 	})
 }
 
+// getBlockHeaderByHash retrieves a block header from database by its hash
+func getBlockHeaderByHash(txn db.Transaction, hash *felt.Felt) (header *core.Header, err error) {
+	return header, txn.Get(db.BlockHeaderNumbersByHash.Key(hash.Marshal()), func(val []byte) error {
+		header, err = getBlockHeaderByNumber(txn, binary.BigEndian.Uint64(val))
+		return err
+	})
+}
+
 func storeStateUpdate(txn db.Transaction, blockNumber uint64, update *core.StateUpdate) error {
 	numBytes := make([]byte, lenOfByteSlice)
 	binary.BigEndian.PutUint64(numBytes, blockNumber)
@@ -415,6 +655,18 @@ func storeTransactionAndReceipt(txn db.Transaction, number, i uint64, t core.Tra
 	return nil
 }
 
+// deleteTransactionAndReceipt removes the entries written by [storeTransactionAndReceipt].
+func deleteTransactionAndReceipt(txn db.Transaction, number, i uint64, r *core.TransactionReceipt) error {
+	bnIndexBytes := (&txAndReceiptDBKey{number, i}).MarshalBinary()
+
+	if err := txn.Delete(db.TransactionBlockNumbersAndIndicesByHash.Key(r.TransactionHash.Marshal())); err != nil {
+		return err
+	} else if err = txn.Delete(db.TransactionsByBlockNumberAndIndex.Key(bnIndexBytes)); err != nil {
+		return err
+	}
+	return txn.Delete(db.ReceiptsByBlockNumberAndIndex.Key(bnIndexBytes))
+}
+
 // getTransactionBlockNumberAndIndexByHash gets the block number and index for a given transaction hash
 func getTransactionBlockNumberAndIndexByHash(txn db.Transaction, hash *felt.Felt) (bnIndex *txAndReceiptDBKey, err error) {
 	return bnIndex, txn.Get(db.TransactionBlockNumbersAndIndicesByHash.Key(hash.Marshal()), func(val []byte) error {
@@ -439,15 +691,6 @@ func getTransactionByHash(txn db.Transaction, hash *felt.Felt) (core.Transaction
 	return getTransactionByBlockNumberAndIndex(txn, bnIndex)
 }
 
-// getReceiptByHash gets the transaction receipt for a given hash.
-func getReceiptByHash(txn db.Transaction, hash *felt.Felt) (*core.TransactionReceipt, error) {
-	if bnIndex, err := getTransactionBlockNumberAndIndexByHash(txn, hash); err != nil {
-		return nil, err
-	} else {
-		return getReceiptByBlockNumberAndIndex(txn, bnIndex)
-	}
-}
-
 // getReceiptByBlockNumberAndIndex gets the transaction receipt for a given block number and index.
 func getReceiptByBlockNumberAndIndex(txn db.Transaction, bnIndex *txAndReceiptDBKey) (r *core.TransactionReceipt, err error) {
 	return r, txn.Get(db.ReceiptsByBlockNumberAndIndex.Key(bnIndex.MarshalBinary()), func(val []byte) error {
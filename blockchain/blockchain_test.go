@@ -91,6 +91,10 @@ func TestGetBlockByNumberAndHash(t *testing.T) {
 		storedByHash, err := chain.GetBlockByHash(block.Hash)
 		require.NoError(t, err)
 		assert.Equal(t, block, storedByHash)
+
+		headerByHash, err := chain.GetBlockHeaderByHash(block.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, &block.Header, headerByHash)
 	})
 	t.Run("GetBlockByNumber returns error if block doesn't exist", func(t *testing.T) {
 		_, err := chain.GetBlockByNumber(42)
@@ -101,6 +105,8 @@ func TestGetBlockByNumberAndHash(t *testing.T) {
 		require.NoError(t, err)
 		_, err = chain.GetBlockByHash(f)
 		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
+		_, err = chain.GetBlockHeaderByHash(f)
+		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
 	})
 }
 
@@ -151,7 +157,9 @@ func TestVerifyBlock(t *testing.T) {
 		expectedErr := blockchain.ErrIncompatibleBlock{
 			Err: errors.New("block's parent hash does not match head block hash"),
 		}
-		assert.EqualError(t, chain.VerifyBlock(incomingBlock), expectedErr.Error())
+		err := chain.VerifyBlock(incomingBlock)
+		assert.EqualError(t, err, expectedErr.Error())
+		assert.ErrorIs(t, err, blockchain.ErrParentDoesNotMatchHead)
 	})
 }
 
@@ -255,6 +263,190 @@ func TestStore(t *testing.T) {
 	})
 }
 
+func TestRevertHead(t *testing.T) {
+	gw, closeFn := testsource.NewTestGateway(utils.MAINNET)
+	defer closeFn()
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		assert.ErrorIs(t, chain.RevertHead(), db.ErrKeyNotFound)
+	})
+
+	var blocks []*core.Block
+	var stateUpdates []*core.StateUpdate
+	for i := uint64(0); i < 3; i++ {
+		block, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		stateUpdate, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(block, stateUpdate, nil))
+		blocks = append(blocks, block)
+		stateUpdates = append(stateUpdates, stateUpdate)
+	}
+
+	t.Run("head is removed and the state is reverted", func(t *testing.T) {
+		require.NoError(t, chain.RevertHead())
+
+		head, err := chain.Head()
+		require.NoError(t, err)
+		assert.Equal(t, blocks[1], head)
+
+		root, err := chain.StateCommitment()
+		require.NoError(t, err)
+		assert.Equal(t, blocks[1].GlobalStateRoot, root)
+
+		_, err = chain.GetBlockByNumber(2)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, err = chain.GetBlockByHash(blocks[2].Hash)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, err = chain.GetBlockHeaderByHash(blocks[2].Hash)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, err = chain.GetStateUpdateByNumber(2)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		for _, txn := range blocks[2].Transactions {
+			_, err = chain.GetTransactionByHash(txn.Hash())
+			assert.ErrorIs(t, err, db.ErrKeyNotFound)
+			_, _, _, err = chain.GetReceipt(txn.Hash())
+			assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		}
+
+		for addr, diff := range stateUpdates[2].StateDiff.StorageDiffs {
+			addr := addr
+			for _, pair := range diff {
+				value, err := chain.ContractStorageAt(&addr, pair.Key, 2)
+				if errors.Is(err, db.ErrKeyNotFound) {
+					continue // deployed in block 2
+				}
+				require.NoError(t, err)
+				want, err := chain.ContractStorageAt(&addr, pair.Key, 1)
+				require.NoError(t, err)
+				assert.Equal(t, want, value)
+			}
+		}
+	})
+
+	t.Run("reverted block can be stored again", func(t *testing.T) {
+		require.NoError(t, chain.Store(blocks[2], stateUpdates[2], nil))
+		head, err := chain.Head()
+		require.NoError(t, err)
+		assert.Equal(t, blocks[2], head)
+	})
+
+	t.Run("revert down to an empty blockchain", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, chain.RevertHead())
+		}
+
+		_, err := chain.Height()
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		root, err := chain.StateCommitment()
+		require.NoError(t, err)
+		assert.Equal(t, new(felt.Felt), root)
+
+		require.NoError(t, chain.Store(blocks[0], stateUpdates[0], nil))
+	})
+}
+
+func TestPending(t *testing.T) {
+	gw, closeFn := testsource.NewTestGateway(utils.MAINNET)
+	defer closeFn()
+
+	block0, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	stateUpdate0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	block1, err := gw.BlockByNumber(context.Background(), 1)
+	require.NoError(t, err)
+	stateUpdate1, err := gw.StateUpdate(context.Background(), 1)
+	require.NoError(t, err)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	require.NoError(t, chain.Store(block0, stateUpdate0, nil))
+
+	_, err = chain.Pending()
+	assert.ErrorIs(t, err, blockchain.ErrPendingBlockNotFound)
+
+	deployedAddr := stateUpdate1.StateDiff.DeployedContracts[0].Address
+	classHash := stateUpdate1.StateDiff.DeployedContracts[0].ClassHash
+	pending := &blockchain.Pending{
+		Block:       block1,
+		StateUpdate: stateUpdate1,
+		NewClasses:  map[felt.Felt]*core.Class{*classHash: {Program: "program"}},
+	}
+
+	t.Run("pending block must be built on top of the head", func(t *testing.T) {
+		assert.Error(t, chain.StorePending(&blockchain.Pending{Block: block0, StateUpdate: stateUpdate0}))
+	})
+
+	t.Run("store and get pending block", func(t *testing.T) {
+		require.NoError(t, chain.StorePending(pending))
+		got, err := chain.Pending()
+		require.NoError(t, err)
+		assert.Equal(t, pending, got)
+	})
+
+	t.Run("pending state", func(t *testing.T) {
+		gotClassHash, found := pending.ContractClassHash(deployedAddr)
+		assert.True(t, found)
+		assert.Equal(t, classHash, gotClassHash)
+		_, found = pending.ContractClassHash(new(felt.Felt).SetUint64(1))
+		assert.False(t, found)
+
+		nonce, found := pending.ContractNonce(deployedAddr)
+		assert.True(t, found)
+		assert.Equal(t, &felt.Zero, nonce)
+
+		for addr, diffs := range stateUpdate1.StateDiff.StorageDiffs {
+			addr := addr
+			for _, diff := range diffs {
+				value, found := pending.ContractStorage(&addr, diff.Key)
+				assert.True(t, found)
+				assert.Equal(t, diff.Value, value)
+			}
+		}
+		_, found = pending.ContractStorage(new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(1))
+		assert.False(t, found)
+
+		class, found := pending.Class(classHash)
+		assert.True(t, found)
+		assert.Equal(t, "program", class.Program)
+
+		txn, receipt, found := pending.Transaction(block1.Transactions[0].Hash())
+		assert.True(t, found)
+		assert.Equal(t, block1.Transactions[0], txn)
+		assert.Equal(t, block1.Receipts[0], receipt)
+	})
+
+	t.Run("pending block is stale once a new head is stored", func(t *testing.T) {
+		require.NoError(t, chain.Store(block1, stateUpdate1, nil))
+		_, err := chain.Pending()
+		assert.ErrorIs(t, err, blockchain.ErrPendingBlockNotFound)
+	})
+}
+
+func TestL1Head(t *testing.T) {
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	_, err := chain.L1Head()
+	assert.ErrorIs(t, err, db.ErrKeyNotFound)
+
+	hash, _ := new(felt.Felt).SetRandom()
+	root, _ := new(felt.Felt).SetRandom()
+	head := &core.L1Head{BlockNumber: 5, BlockHash: hash, StateRoot: root, L1BlockNumber: 100}
+	require.NoError(t, chain.SetL1Head(head))
+
+	got, err := chain.L1Head()
+	require.NoError(t, err)
+	assert.Equal(t, head, got)
+
+	_, err = chain.L1ScannedHeight()
+	assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	require.NoError(t, chain.SetL1ScannedHeight(120))
+	height, err := chain.L1ScannedHeight()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(120), height)
+}
+
 func TestGetTransactionAndReceipt(t *testing.T) {
 	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
 
@@ -284,8 +476,9 @@ func TestGetTransactionAndReceipt(t *testing.T) {
 	})
 
 	t.Run("GetTransactionReceipt returns error if receipt does not exist", func(t *testing.T) {
-		r, err := chain.GetReceipt(new(felt.Felt).SetUint64(234))
+		r, blockHash, _, err := chain.GetReceipt(new(felt.Felt).SetUint64(234))
 		assert.Nil(t, r)
+		assert.Nil(t, blockHash)
 		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
 	})
 
@@ -315,12 +508,152 @@ func TestGetTransactionAndReceipt(t *testing.T) {
 				require.NoError(t, err)
 
 				for _, expectedR := range block.Receipts {
-					gotR, err := chain.GetReceipt(expectedR.TransactionHash)
+					gotR, blockHash, blockNumber, err := chain.GetReceipt(expectedR.TransactionHash)
 					require.NoError(t, err)
 					assert.Equal(t, expectedR, gotR)
+					assert.Equal(t, block.Hash, blockHash)
+					assert.Equal(t, block.Number, blockNumber)
 
 				}
 			})
 		}
 	})
 }
+
+func TestEvents(t *testing.T) {
+	gw, closeFn := testsource.NewTestGateway(utils.GOERLI)
+	defer closeFn()
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.GOERLI)
+	emptyStateUpdate := &core.StateUpdate{
+		OldRoot:   new(felt.Felt),
+		NewRoot:   new(felt.Felt),
+		StateDiff: new(core.StateDiff),
+	}
+
+	// goerli blocks with events are stored at the start of the chain to avoid
+	// having to apply their state updates
+	var allEvents []*core.Event
+	parentHash := new(felt.Felt)
+	for i, number := range []uint64{119801, 119802} {
+		block, err := gw.BlockByNumber(context.Background(), number)
+		require.NoError(t, err)
+		block.Number = uint64(i)
+		block.ParentHash = parentHash
+		parentHash = block.Hash
+		require.NoError(t, chain.Store(block, emptyStateUpdate, nil))
+
+		for _, receipt := range block.Receipts {
+			allEvents = append(allEvents, receipt.Events...)
+		}
+	}
+	require.NotEmpty(t, allEvents)
+
+	collect := func(t *testing.T, filter *blockchain.EventFilter, chunkSize uint64) []*core.Event {
+		var events []*core.Event
+		start := blockchain.EventPosition{BlockNumber: filter.FromBlock}
+		for {
+			chunk, next, err := chain.Events(filter, start, chunkSize, 0)
+			require.NoError(t, err)
+			require.LessOrEqual(t, uint64(len(chunk)), chunkSize)
+			for _, event := range chunk {
+				events = append(events, event.Event)
+			}
+			if next == nil {
+				return events
+			}
+			start = *next
+		}
+	}
+
+	t.Run("no filter returns all events", func(t *testing.T) {
+		filter := &blockchain.EventFilter{FromBlock: 0, ToBlock: 100}
+		assert.Equal(t, allEvents, collect(t, filter, 1024))
+		assert.Equal(t, allEvents, collect(t, filter, 7))
+		assert.Equal(t, allEvents, collect(t, filter, 1))
+	})
+
+	t.Run("block range", func(t *testing.T) {
+		events, next, err := chain.Events(&blockchain.EventFilter{FromBlock: 1, ToBlock: 1}, blockchain.EventPosition{BlockNumber: 1}, 1024, 0)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		for _, event := range events {
+			assert.Equal(t, uint64(1), event.BlockNumber)
+		}
+		assert.Equal(t, allEvents[len(allEvents)-len(events):], collect(t, &blockchain.EventFilter{FromBlock: 1, ToBlock: 1}, 3))
+	})
+
+	t.Run("filter by address and keys", func(t *testing.T) {
+		address := allEvents[0].From
+		key := allEvents[len(allEvents)-1].Keys[0]
+
+		var byAddress, byKey, byBoth []*core.Event
+		for _, event := range allEvents {
+			hasKey := false
+			for _, eventKey := range event.Keys {
+				hasKey = hasKey || eventKey.Equal(key)
+			}
+			if event.From.Equal(address) {
+				byAddress = append(byAddress, event)
+			}
+			if hasKey {
+				byKey = append(byKey, event)
+			}
+			if event.From.Equal(address) && hasKey {
+				byBoth = append(byBoth, event)
+			}
+		}
+
+		assert.Equal(t, byAddress, collect(t, &blockchain.EventFilter{ToBlock: 1, Address: address}, 2))
+		assert.Equal(t, byKey, collect(t, &blockchain.EventFilter{ToBlock: 1, Keys: []*felt.Felt{key}}, 2))
+		assert.Equal(t, byBoth, collect(t, &blockchain.EventFilter{ToBlock: 1, Address: address, Keys: []*felt.Felt{key}}, 2))
+
+		unknown := new(felt.Felt).SetUint64(0xdead)
+		assert.Empty(t, collect(t, &blockchain.EventFilter{ToBlock: 1, Address: unknown}, 2))
+	})
+
+	t.Run("filter by key position", func(t *testing.T) {
+		key := allEvents[len(allEvents)-1].Keys[0]
+		var byFirstKey []*core.Event
+		for _, event := range allEvents {
+			if len(event.Keys) > 0 && event.Keys[0].Equal(key) {
+				byFirstKey = append(byFirstKey, event)
+			}
+		}
+		require.NotEmpty(t, byFirstKey)
+
+		filter := &blockchain.EventFilter{ToBlock: 1, PositionalKeys: [][]*felt.Felt{{key}}}
+		assert.Equal(t, byFirstKey, collect(t, filter, 2))
+		// an empty position matches any key
+		assert.Equal(t, allEvents, collect(t, &blockchain.EventFilter{ToBlock: 1, PositionalKeys: [][]*felt.Felt{{}}}, 2))
+		// keys are only compared at their position
+		var bySecondKey []*core.Event
+		for _, event := range allEvents {
+			if len(event.Keys) > 1 && event.Keys[1].Equal(key) {
+				bySecondKey = append(bySecondKey, event)
+			}
+		}
+		assert.Equal(t, bySecondKey, collect(t, &blockchain.EventFilter{ToBlock: 1, PositionalKeys: [][]*felt.Felt{{}, {key}}}, 2))
+	})
+
+	t.Run("scanned blocks are capped", func(t *testing.T) {
+		filter := &blockchain.EventFilter{FromBlock: 0, ToBlock: 1}
+		events, next, err := chain.Events(filter, blockchain.EventPosition{}, 1024, 1)
+		require.NoError(t, err)
+		require.NotNil(t, next)
+		assert.Equal(t, blockchain.EventPosition{BlockNumber: 1}, *next)
+		for _, event := range events {
+			assert.Equal(t, uint64(0), event.BlockNumber)
+		}
+
+		rest, next, err := chain.Events(filter, *next, 1024, 1)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		assert.Equal(t, len(allEvents), len(events)+len(rest))
+	})
+
+	t.Run("start position out of range", func(t *testing.T) {
+		_, _, err := chain.Events(&blockchain.EventFilter{FromBlock: 1, ToBlock: 1}, blockchain.EventPosition{}, 10, 0)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,235 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/bits-and-blooms/bloom/v3"
+)
+
+const (
+	// minEventBloomCapacity is the minimum number of entries a block's event bloom filter is sized for
+	minEventBloomCapacity = 64
+	// eventBloomFalsePositiveRate is the desired false positive rate of a block's event bloom filter
+	eventBloomFalsePositiveRate = 0.01
+)
+
+// EventFilter selects the events emitted between FromBlock and ToBlock (both inclusive).
+// If Address is set only events emitted by that contract are selected. If Keys is not
+// empty only events that have at least one of the given keys are selected. If PositionalKeys
+// is not empty only events whose i-th key is one of PositionalKeys[i] are selected, where an
+// empty PositionalKeys[i] matches any key.
+type EventFilter struct {
+	FromBlock      uint64
+	ToBlock        uint64
+	Address        *felt.Felt
+	Keys           []*felt.Felt
+	PositionalKeys [][]*felt.Felt
+}
+
+func (f *EventFilter) matches(event *core.Event) bool {
+	if f.Address != nil && !f.Address.Equal(event.From) {
+		return false
+	}
+	for i, keys := range f.PositionalKeys {
+		if len(keys) == 0 {
+			continue
+		}
+		if i >= len(event.Keys) || !containsFelt(keys, event.Keys[i]) {
+			return false
+		}
+	}
+	if len(f.Keys) == 0 {
+		return true
+	}
+	for _, key := range event.Keys {
+		if containsFelt(f.Keys, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFelt(felts []*felt.Felt, x *felt.Felt) bool {
+	for _, f := range felts {
+		if f.Equal(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayMatch reports whether a block with the given event bloom filter can contain matching events
+func (f *EventFilter) mayMatch(filter *bloom.BloomFilter) bool {
+	if f.Address != nil && !filter.Test(f.Address.Marshal()) {
+		return false
+	}
+	for _, keys := range f.PositionalKeys {
+		if len(keys) > 0 && !mayContainAny(filter, keys) {
+			return false
+		}
+	}
+	return len(f.Keys) == 0 || mayContainAny(filter, f.Keys)
+}
+
+func mayContainAny(filter *bloom.BloomFilter, keys []*felt.Felt) bool {
+	for _, key := range keys {
+		if filter.Test(key.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
+
+// EventPosition identifies an event by the number of the block that includes it and
+// its index among all the events emitted in that block.
+type EventPosition struct {
+	BlockNumber uint64
+	Index       uint64
+}
+
+type FilteredEvent struct {
+	*core.Event
+	BlockNumber     uint64
+	BlockHash       *felt.Felt
+	TransactionHash *felt.Felt
+}
+
+// Events returns up to chunkSize events that match the filter, starting from the event at
+// position start and looking at no more than maxBlocks blocks, zero means no limit.
+// start.BlockNumber must be within the range of the filter. If there are more matching events,
+// or blocks left to look at, the position to continue from is returned as well. If the filter
+// range goes past the chain height, the events of the pending block are included as if it had
+// the number height+1; their BlockHash is nil.
+func (b *Blockchain) Events(filter *EventFilter, start EventPosition, chunkSize, maxBlocks uint64) (
+	events []*FilteredEvent, next *EventPosition, err error,
+) {
+	if start.BlockNumber < filter.FromBlock || start.BlockNumber > filter.ToBlock {
+		return nil, nil, errors.New("start position is out of the filter range")
+	}
+
+	return events, next, b.database.View(func(txn db.Transaction) error {
+		height, err := b.height(txn)
+		if err != nil {
+			return err
+		}
+
+		toBlock := filter.ToBlock
+		pending, pendingErr := b.pendingBlock(txn)
+		if toBlock > height {
+			toBlock = height
+			if pendingErr == nil {
+				toBlock = height + 1
+			}
+		}
+
+		for number := start.BlockNumber; number <= toBlock; number++ {
+			if maxBlocks > 0 && number-start.BlockNumber == maxBlocks {
+				next = &EventPosition{BlockNumber: number}
+				return nil
+			}
+
+			var blockHash *felt.Felt
+			var receipts []*core.TransactionReceipt
+			if number > height {
+				receipts = pending.Block.Receipts
+			} else {
+				if eventFilter, err := getEventFilterByBlockNumber(txn, number); err == nil {
+					if !filter.mayMatch(eventFilter) {
+						continue
+					}
+				} else if !errors.Is(err, db.ErrKeyNotFound) {
+					return err
+				}
+
+				header, err := getBlockHeaderByNumber(txn, number)
+				if err != nil {
+					return err
+				}
+				blockHash = header.Hash
+				if receipts, err = getReceiptsByBlockNumber(txn, number); err != nil {
+					return err
+				}
+			}
+
+			index := uint64(0)
+			for _, receipt := range receipts {
+				for _, event := range receipt.Events {
+					if number == start.BlockNumber && index < start.Index {
+						index++
+						continue
+					}
+
+					if filter.matches(event) {
+						if uint64(len(events)) == chunkSize {
+							next = &EventPosition{BlockNumber: number, Index: index}
+							return nil
+						}
+
+						events = append(events, &FilteredEvent{
+							Event:           event,
+							BlockNumber:     number,
+							BlockHash:       blockHash,
+							TransactionHash: receipt.TransactionHash,
+						})
+					}
+					index++
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// storeEventFilter stores a bloom filter of the addresses and keys of all the events emitted in
+// the given block. The db storage for event filters is maintained as follows:
+//
+// [db.EventFiltersByBlockNumber](BlockNumber) -> (BloomFilter)
+//
+// "[]" is the db prefix to represent a bucket
+// "()" are additional keys appended to the prefix or multiple values marshalled together
+// "->" represents a key value pair.
+func storeEventFilter(txn db.Transaction, blockNumber uint64, receipts []*core.TransactionReceipt) error {
+	entries := uint(0)
+	for _, receipt := range receipts {
+		for _, event := range receipt.Events {
+			entries += uint(len(event.Keys)) + 1
+		}
+	}
+	if entries < minEventBloomCapacity {
+		entries = minEventBloomCapacity
+	}
+
+	filter := bloom.NewWithEstimates(entries, eventBloomFalsePositiveRate)
+	for _, receipt := range receipts {
+		for _, event := range receipt.Events {
+			filter.Add(event.From.Marshal())
+			for _, key := range event.Keys {
+				filter.Add(key.Marshal())
+			}
+		}
+	}
+
+	numBytes := make([]byte, lenOfByteSlice)
+	binary.BigEndian.PutUint64(numBytes, blockNumber)
+
+	if filterBytes, err := filter.GobEncode(); err != nil {
+		return err
+	} else if err = txn.Set(db.EventFiltersByBlockNumber.Key(numBytes), filterBytes); err != nil {
+		return err
+	}
+	return nil
+}
+
+func getEventFilterByBlockNumber(txn db.Transaction, blockNumber uint64) (filter *bloom.BloomFilter, err error) {
+	numBytes := make([]byte, lenOfByteSlice)
+	binary.BigEndian.PutUint64(numBytes, blockNumber)
+
+	return filter, txn.Get(db.EventFiltersByBlockNumber.Key(numBytes), func(val []byte) error {
+		filter = new(bloom.BloomFilter)
+		return filter.GobDecode(val)
+	})
+}
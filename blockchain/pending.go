@@ -0,0 +1,128 @@
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+var ErrPendingBlockNotFound = errors.New("pending block not found")
+
+// Pending is the block that the sequencer is currently building on top of the chain head,
+// along with its state update and the classes it references. It is only kept in memory.
+type Pending struct {
+	Block       *core.Block
+	StateUpdate *core.StateUpdate
+	NewClasses  map[felt.Felt]*core.Class
+}
+
+// ContractStorage returns the value the pending block sets at the storage slot key of the
+// contract at addr. A contract deployed in the pending block has all its other slots set to
+// zero. If the pending block does not change the slot, false is returned.
+func (p *Pending) ContractStorage(addr, key *felt.Felt) (*felt.Felt, bool) {
+	diffs := p.StateUpdate.StateDiff.StorageDiffs[*addr]
+	for i := len(diffs) - 1; i >= 0; i-- {
+		if diffs[i].Key.Equal(key) {
+			return diffs[i].Value, true
+		}
+	}
+	if _, deployed := p.ContractClassHash(addr); deployed {
+		return new(felt.Felt), true
+	}
+	return nil, false
+}
+
+// ContractNonce returns the nonce the pending block sets for the contract at addr.
+// If the pending block does not change the nonce, false is returned.
+func (p *Pending) ContractNonce(addr *felt.Felt) (*felt.Felt, bool) {
+	if nonce, found := p.StateUpdate.StateDiff.Nonces[*addr]; found {
+		return nonce, true
+	}
+	if _, deployed := p.ContractClassHash(addr); deployed {
+		return new(felt.Felt), true
+	}
+	return nil, false
+}
+
+// ContractClassHash returns the class hash of the contract at addr if it is deployed
+// in the pending block.
+func (p *Pending) ContractClassHash(addr *felt.Felt) (*felt.Felt, bool) {
+	for _, contract := range p.StateUpdate.StateDiff.DeployedContracts {
+		if contract.Address.Equal(addr) {
+			return contract.ClassHash, true
+		}
+	}
+	return nil, false
+}
+
+// Class returns the class with the given hash if it is referenced by the pending block.
+func (p *Pending) Class(classHash *felt.Felt) (*core.Class, bool) {
+	class, found := p.NewClasses[*classHash]
+	return class, found && class != nil
+}
+
+// Transaction returns the transaction with the given hash and its receipt if it is
+// included in the pending block.
+func (p *Pending) Transaction(hash *felt.Felt) (core.Transaction, *core.TransactionReceipt, bool) {
+	for i, txn := range p.Block.Transactions {
+		if txn.Hash().Equal(hash) {
+			return txn, p.Block.Receipts[i], true
+		}
+	}
+	return nil, nil, false
+}
+
+// StorePending sets the pending block. It is rejected if it is not built on top of the
+// current chain head.
+func (b *Blockchain) StorePending(pending *Pending) error {
+	return b.database.View(func(txn db.Transaction) error {
+		if err := b.checkPendingParent(txn, pending); err != nil {
+			return err
+		}
+
+		b.pendingLock.Lock()
+		defer b.pendingLock.Unlock()
+		b.pending = pending
+		return nil
+	})
+}
+
+// Pending returns the pending block. Once a new head is stored the previous pending block
+// is stale and [ErrPendingBlockNotFound] is returned until a new one is set.
+func (b *Blockchain) Pending() (pending *Pending, err error) {
+	return pending, b.database.View(func(txn db.Transaction) error {
+		pending, err = b.pendingBlock(txn)
+		return err
+	})
+}
+
+func (b *Blockchain) pendingBlock(txn db.Transaction) (*Pending, error) {
+	b.pendingLock.RLock()
+	pending := b.pending
+	b.pendingLock.RUnlock()
+
+	if pending == nil || b.checkPendingParent(txn, pending) != nil {
+		return nil, ErrPendingBlockNotFound
+	}
+	return pending, nil
+}
+
+func (b *Blockchain) checkPendingParent(txn db.Transaction, pending *Pending) error {
+	headHash := new(felt.Felt)
+	if height, err := b.height(txn); err == nil {
+		header, err := getBlockHeaderByNumber(txn, height)
+		if err != nil {
+			return err
+		}
+		headHash = header.Hash
+	} else if !errors.Is(err, db.ErrKeyNotFound) {
+		return err
+	}
+
+	if !pending.Block.ParentHash.Equal(headHash) {
+		return ErrIncompatibleBlock{errors.New("pending block parent hash does not match the chain head")}
+	}
+	return nil
+}
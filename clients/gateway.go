@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"time"
 
@@ -17,6 +18,15 @@ import (
 
 type Backoff func(wait time.Duration) time.Duration
 
+// EventListener is notified of every request the GatewayClient sends
+type EventListener interface {
+	// OnRequest is called after every attempt at a request to endpoint, err is nil if the
+	// attempt succeeded
+	OnRequest(endpoint string, took time.Duration, err error)
+	// OnRetry is called every time a failed request to endpoint is retried
+	OnRetry(endpoint string)
+}
+
 type GatewayClient struct {
 	url        string
 	client     *http.Client
@@ -25,6 +35,7 @@ type GatewayClient struct {
 	maxWait    time.Duration
 	minWait    time.Duration
 	log        utils.SimpleLogger
+	listener   EventListener
 }
 
 func (c *GatewayClient) WithBackoff(b Backoff) *GatewayClient {
@@ -52,6 +63,11 @@ func (c *GatewayClient) WithLogger(log utils.SimpleLogger) *GatewayClient {
 	return c
 }
 
+func (c *GatewayClient) WithListener(l EventListener) *GatewayClient {
+	c.listener = l
+	return c
+}
+
 func ExponentialBackoff(wait time.Duration) time.Duration {
 	return wait * 2
 }
@@ -106,12 +122,17 @@ func (c *GatewayClient) get(ctx context.Context, queryUrl string) ([]byte, error
 				return nil, err
 			}
 
+			start := time.Now()
 			res, err = c.client.Do(req)
 			if err == nil && res != nil && res.StatusCode == http.StatusOK {
-				return io.ReadAll(res.Body)
+				var body []byte
+				body, err = io.ReadAll(res.Body)
+				c.onRequest(req.URL, time.Since(start), err)
+				return body, err
 			} else if res != nil && res.StatusCode != http.StatusOK {
 				err = errors.New(res.Status)
 			}
+			c.onRequest(req.URL, time.Since(start), err)
 
 			if wait < c.minWait {
 				wait = c.minWait
@@ -121,11 +142,25 @@ func (c *GatewayClient) get(ctx context.Context, queryUrl string) ([]byte, error
 				wait = c.maxWait
 			}
 			c.log.Warnw("failed query to feeder gateway, retrying...", "retryAfter", wait.String())
+			if c.listener != nil && i < c.maxRetries {
+				c.listener.OnRetry(endpointName(req.URL))
+			}
 		}
 	}
 	return nil, err
 }
 
+func (c *GatewayClient) onRequest(reqURL *url.URL, took time.Duration, err error) {
+	if c.listener != nil {
+		c.listener.OnRequest(endpointName(reqURL), took, err)
+	}
+}
+
+// endpointName returns the name of the feeder gateway endpoint that reqURL points to
+func endpointName(reqURL *url.URL) string {
+	return path.Base(reqURL.Path)
+}
+
 // StateUpdate object returned by the gateway in JSON format for "get_state_update" endpoint
 type StateUpdate struct {
 	BlockHash *felt.Felt `json:"block_hash"`
@@ -148,8 +183,17 @@ type StateUpdate struct {
 }
 
 func (c *GatewayClient) GetStateUpdate(ctx context.Context, blockNumber uint64) (*StateUpdate, error) {
+	return c.getStateUpdate(ctx, strconv.FormatUint(blockNumber, 10))
+}
+
+// GetPendingStateUpdate returns the state update of the pending block
+func (c *GatewayClient) GetPendingStateUpdate(ctx context.Context) (*StateUpdate, error) {
+	return c.getStateUpdate(ctx, "pending")
+}
+
+func (c *GatewayClient) getStateUpdate(ctx context.Context, blockNumber string) (*StateUpdate, error) {
 	queryUrl := c.buildQueryString("get_state_update", map[string]string{
-		"blockNumber": strconv.FormatUint(blockNumber, 10),
+		"blockNumber": blockNumber,
 	})
 
 	if body, err := c.get(ctx, queryUrl); err != nil {
@@ -265,8 +309,22 @@ type Block struct {
 }
 
 func (c *GatewayClient) GetBlock(ctx context.Context, blockNumber uint64) (*Block, error) {
+	return c.getBlock(ctx, strconv.FormatUint(blockNumber, 10))
+}
+
+// GetPendingBlock returns the block that the sequencer is currently building
+func (c *GatewayClient) GetPendingBlock(ctx context.Context) (*Block, error) {
+	return c.getBlock(ctx, "pending")
+}
+
+// GetLatestBlock returns the most recent block accepted on L2
+func (c *GatewayClient) GetLatestBlock(ctx context.Context) (*Block, error) {
+	return c.getBlock(ctx, "latest")
+}
+
+func (c *GatewayClient) getBlock(ctx context.Context, blockNumber string) (*Block, error) {
 	queryUrl := c.buildQueryString("get_block", map[string]string{
-		"blockNumber": strconv.FormatUint(blockNumber, 10),
+		"blockNumber": blockNumber,
 	})
 
 	if body, err := c.get(ctx, queryUrl); err != nil {
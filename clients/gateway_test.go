@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/NethermindEth/juno/testsource"
 	"github.com/NethermindEth/juno/utils"
@@ -495,6 +496,30 @@ func TestGetBlock(t *testing.T) {
 		assert.Nil(t, actualBlock, "Unexpected error")
 		assert.NotNil(t, err)
 	})
+	t.Run("Test pending block", func(t *testing.T) {
+		pendingBlock, err := gatewayClient.GetPendingBlock(context.Background())
+		assert.Equal(t, nil, err, "Unexpected error")
+		assert.Equal(t, "PENDING", pendingBlock.Status)
+		assert.Nil(t, pendingBlock.Hash)
+		assert.NotNil(t, pendingBlock.ParentHash)
+	})
+	t.Run("Test latest block", func(t *testing.T) {
+		latestBlock, err := gatewayClient.GetLatestBlock(context.Background())
+		assert.Equal(t, nil, err, "Unexpected error")
+		assert.Equal(t, uint64(2), latestBlock.Number)
+		assert.NotNil(t, latestBlock.Hash)
+	})
+}
+
+func TestGetPendingStateUpdate(t *testing.T) {
+	gatewayClient, closeFn := testsource.NewTestClient(utils.MAINNET)
+	defer closeFn()
+
+	pendingUpdate, err := gatewayClient.GetPendingStateUpdate(context.Background())
+	assert.Equal(t, nil, err, "Unexpected error")
+	assert.Nil(t, pendingUpdate.BlockHash)
+	assert.NotNil(t, pendingUpdate.OldRoot)
+	assert.Equal(t, 1, len(pendingUpdate.StateDiff.DeployedContracts))
 }
 
 func TestGetClassDefinition(t *testing.T) {
@@ -566,3 +591,48 @@ func TestBackoffFailure(t *testing.T) {
 	assert.EqualError(t, err, "500 Internal Server Error")
 	assert.Equal(t, maxRetries, try-1) // we have retried `maxRetries` times
 }
+
+type recordingListener struct {
+	requests []string
+	failures int
+	retries  int
+}
+
+func (l *recordingListener) OnRequest(endpoint string, took time.Duration, err error) {
+	l.requests = append(l.requests, endpoint)
+	if err != nil {
+		l.failures++
+	}
+}
+
+func (l *recordingListener) OnRetry(endpoint string) {
+	l.retries++
+}
+
+func TestListener(t *testing.T) {
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	listener := new(recordingListener)
+	c := clients.NewGatewayClient(srv.URL).WithBackoff(clients.NopBackoff).WithMaxRetries(2).WithListener(listener)
+
+	_, err := c.GetBlock(context.Background(), 0)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"get_block", "get_block", "get_block"}, listener.requests)
+	assert.Equal(t, 3, listener.failures)
+	assert.Equal(t, 2, listener.retries)
+
+	fail = false
+	_, err = c.GetStateUpdate(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "get_state_update", listener.requests[3])
+	assert.Equal(t, 3, listener.failures)
+	assert.Equal(t, 2, listener.retries)
+}
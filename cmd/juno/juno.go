@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/NethermindEth/juno/node"
 	"github.com/NethermindEth/juno/utils"
@@ -22,21 +23,54 @@ Juno is a Go implementation of a Starknet full node client created by Nethermind
 `
 
 const (
-	configF    = "config"
-	verbosityF = "verbosity"
-	rpcPortF   = "rpc-port"
-	metricsF   = "metrics"
-	dbPathF    = "db-path"
-	networkF   = "network"
-	ethNodeF   = "eth-node"
-
-	defaultConfig    = ""
-	defaultVerbosity = utils.INFO
-	defaultRpcPort   = uint16(6060)
-	defaultMetrics   = false
-	defaultDbPath    = ""
-	defaultNetwork   = utils.MAINNET
-	defaultEthNode   = ""
+	configF                = "config"
+	verbosityF             = "verbosity"
+	rpcHostF               = "rpc-host"
+	rpcPortF               = "rpc-port"
+	wsF                    = "ws"
+	wsPortF                = "ws-port"
+	rpcTlsCertF            = "rpc-tls-cert"
+	rpcTlsKeyF             = "rpc-tls-key"
+	rpcCorsOriginsF        = "rpc-cors-origins"
+	rpcMaxBodySizeF        = "rpc-max-body-size"
+	rpcMaxBatchSizeF       = "rpc-max-batch-size"
+	rpcRateLimitF          = "rpc-rate-limit"
+	rpcMaxInFlightF        = "rpc-max-in-flight"
+	readyMaxLagF           = "ready-max-lag"
+	ipcPathF               = "ipc-path"
+	syncMaxLookaheadF      = "sync-max-lookahead"
+	syncMaxLookaheadBytesF = "sync-max-lookahead-bytes"
+	sourceF                = "source"
+	sourceQuorumF          = "source-quorum"
+	metricsF               = "metrics"
+	dbPathF                = "db-path"
+	networkF               = "network"
+	ethNodeF               = "eth-node"
+	rpcTimeoutF            = "rpc-timeout"
+	rpcMethodTimeoutsF     = "rpc-method-timeouts"
+
+	defaultConfig                = ""
+	defaultVerbosity             = utils.INFO
+	defaultRpcHost               = "0.0.0.0"
+	defaultRpcPort               = uint16(6060)
+	defaultWs                    = false
+	defaultWsPort                = uint16(6061)
+	defaultRpcTlsCert            = ""
+	defaultRpcTlsKey             = ""
+	defaultRpcMaxBodySize        = uint(10 * 1024 * 1024)
+	defaultRpcMaxBatchSize       = uint(1000)
+	defaultRpcRateLimit          = uint(0)
+	defaultRpcMaxInFlight        = uint(512)
+	defaultReadyMaxLag           = uint(10)
+	defaultIpcPath               = ""
+	defaultSyncMaxLookahead      = uint(128)
+	defaultSyncMaxLookaheadBytes = uint(512 * 1024 * 1024)
+	defaultSourceQuorum          = uint(1)
+	defaultMetrics               = false
+	defaultDbPath                = ""
+	defaultNetwork               = utils.MAINNET
+	defaultEthNode               = ""
+	defaultRpcTimeout            = time.Duration(0)
 
 	configFlagUsage    = "The yaml configuration file."
 	verbosityFlagUsage = `Verbosity of the logs. Options:
@@ -47,15 +81,38 @@ const (
 `
 	rpcPortUsage = "The port on which the RPC server will listen for requests. " +
 		"Warning: this exposes the node to external requests and potentially DoS attacks."
-	metricsUsage = "Enables the metrics server and listens on port 9090."
-	dbPathUsage  = "Location of the database files."
-	networkUsage = `Available Starknet networks. Options:
+	wsUsage     = "Enables the websocket server."
+	wsPortUsage = "The port on which the websocket server will listen for JSON-RPC requests. " +
+		"Warning: this exposes the node to external requests and potentially DoS attacks."
+	rpcHostUsage = "The host or IP address on which the RPC and websocket servers listen. " +
+		"Empty listens on all interfaces."
+	rpcTlsCertUsage      = "The PEM file of the TLS certificate presented by the RPC and websocket servers."
+	rpcTlsKeyUsage       = "The PEM file of the private key of the TLS certificate."
+	rpcCorsOriginsUsage  = "The origins of the web pages allowed to call the RPC and websocket servers, * allows any origin."
+	rpcMaxBodySizeUsage  = "The maximum size in bytes of an RPC request body. 0 disables the limit."
+	rpcMaxBatchSizeUsage = "The maximum number of requests in an RPC batch. 0 disables the limit."
+	rpcRateLimitUsage    = "The number of RPC requests per second allowed from a single IP address. " +
+		"0 disables the limit."
+	rpcMaxInFlightUsage        = "The maximum number of RPC requests handled at the same time. 0 disables the limit."
+	readyMaxLagUsage           = "The number of blocks the node can be behind the network and still be reported as ready."
+	ipcPathUsage               = "Location of the IPC socket. Defaults to a socket named after the network in the data directory."
+	syncMaxLookaheadUsage      = "The maximum number of blocks fetched ahead of the latest stored block. 0 disables the limit."
+	syncMaxLookaheadBytesUsage = "The maximum size in bytes of the blocks waiting to be stored. 0 disables the limit."
+	sourceUsage                = "A source to sync from instead of the feeder gateway of the network, either the URL of a feeder gateway " +
+		"or a directory or zip archive of feeder gateway responses. Repeat to fail over from one source to the next."
+	sourceQuorumUsage = "The number of sources that must agree on every block and state update."
+	metricsUsage      = "Enables the metrics server and listens on port 9090."
+	dbPathUsage       = "Location of the database files."
+	networkUsage      = `Available Starknet networks. Options:
 0 = mainnet
 1 = goerli
 2 = goerli2
 3 = integration`
-	ethNodeUsage = "The Ethereum endpoint to synchronise with. " +
-		"If unset feeder gateway will be used."
+	ethNodeUsage = "The Ethereum endpoint used to track which blocks are accepted on L1. " +
+		"If unset, no block will be reported as accepted on L1."
+	rpcTimeoutUsage        = "The deadline of every RPC request, for example 30s. 0 disables the deadline."
+	rpcMethodTimeoutsUsage = "Deadlines of the requests for specific RPC methods that override --rpc-timeout, " +
+		"for example starknet_getEvents=1m."
 )
 
 var (
@@ -71,11 +128,29 @@ func NewCmd(newNodeFn node.NewStarknetNodeFn) *cobra.Command {
 
 	junoCmd.Flags().StringVar(&cfgFile, configF, defaultConfig, configFlagUsage)
 	junoCmd.Flags().Uint8(verbosityF, uint8(defaultVerbosity), verbosityFlagUsage)
+	junoCmd.Flags().String(rpcHostF, defaultRpcHost, rpcHostUsage)
 	junoCmd.Flags().Uint16(rpcPortF, defaultRpcPort, rpcPortUsage)
+	junoCmd.Flags().Bool(wsF, defaultWs, wsUsage)
+	junoCmd.Flags().Uint16(wsPortF, defaultWsPort, wsPortUsage)
+	junoCmd.Flags().String(rpcTlsCertF, defaultRpcTlsCert, rpcTlsCertUsage)
+	junoCmd.Flags().String(rpcTlsKeyF, defaultRpcTlsKey, rpcTlsKeyUsage)
+	junoCmd.Flags().StringSlice(rpcCorsOriginsF, nil, rpcCorsOriginsUsage)
+	junoCmd.Flags().Uint(rpcMaxBodySizeF, defaultRpcMaxBodySize, rpcMaxBodySizeUsage)
+	junoCmd.Flags().Uint(rpcMaxBatchSizeF, defaultRpcMaxBatchSize, rpcMaxBatchSizeUsage)
+	junoCmd.Flags().Uint(rpcRateLimitF, defaultRpcRateLimit, rpcRateLimitUsage)
+	junoCmd.Flags().Uint(rpcMaxInFlightF, defaultRpcMaxInFlight, rpcMaxInFlightUsage)
+	junoCmd.Flags().Uint(readyMaxLagF, defaultReadyMaxLag, readyMaxLagUsage)
+	junoCmd.Flags().String(ipcPathF, defaultIpcPath, ipcPathUsage)
+	junoCmd.Flags().Uint(syncMaxLookaheadF, defaultSyncMaxLookahead, syncMaxLookaheadUsage)
+	junoCmd.Flags().Uint(syncMaxLookaheadBytesF, defaultSyncMaxLookaheadBytes, syncMaxLookaheadBytesUsage)
+	junoCmd.Flags().StringSlice(sourceF, nil, sourceUsage)
+	junoCmd.Flags().Uint(sourceQuorumF, defaultSourceQuorum, sourceQuorumUsage)
 	junoCmd.Flags().Bool(metricsF, defaultMetrics, metricsUsage)
 	junoCmd.Flags().String(dbPathF, defaultDbPath, dbPathUsage)
 	junoCmd.Flags().Uint8(networkF, uint8(defaultNetwork), networkUsage)
 	junoCmd.Flags().String(ethNodeF, defaultEthNode, ethNodeUsage)
+	junoCmd.Flags().Duration(rpcTimeoutF, defaultRpcTimeout, rpcTimeoutUsage)
+	junoCmd.Flags().StringToString(rpcMethodTimeoutsF, nil, rpcMethodTimeoutsUsage)
 
 	junoCmd.RunE = func(cmd *cobra.Command, _ []string) error {
 		v := viper.New()
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	juno "github.com/NethermindEth/juno/cmd/juno"
 	"github.com/NethermindEth/juno/node"
@@ -68,10 +69,28 @@ Juno is a Go implementation of a Starknet full node client created by Nethermind
 		// implementation.
 		defaultVerbosity := utils.INFO
 		defaultRpcPort := uint16(6060)
+		defaultWs := false
+		defaultWsPort := uint16(6061)
+		defaultRpcHost := "0.0.0.0"
+		defaultRpcTlsCert := ""
+		defaultRpcTlsKey := ""
+		defaultRpcCorsOrigins := []string{}
+		defaultRpcMaxBodySize := uint(10 * 1024 * 1024)
+		defaultRpcMaxBatchSize := uint(1000)
+		defaultRpcRateLimit := uint(0)
+		defaultRpcMaxInFlight := uint(512)
+		defaultReadyMaxLag := uint(10)
+		defaultIpcPath := ""
+		defaultSyncMaxLookahead := uint(128)
+		defaultSyncMaxLookaheadBytes := uint(512 * 1024 * 1024)
+		defaultSources := []string{}
+		defaultSourceQuorum := uint(1)
 		defaultMetrics := false
 		defaultDbPath := ""
 		defaultNetwork := utils.MAINNET
 		defaultEthNode := ""
+		defaultRpcTimeout := time.Duration(0)
+		defaultRpcMethodTimeouts := map[string]time.Duration{}
 
 		tests := map[string]struct {
 			cfgFile         func(t *testing.T, cfg string) (string, func())
@@ -83,21 +102,59 @@ Juno is a Go implementation of a Starknet full node client created by Nethermind
 			"default config with no flags": {
 				inputArgs: []string{""},
 				expectedConfig: &node.Config{
-					Verbosity:    defaultVerbosity,
-					RpcPort:      defaultRpcPort,
-					Metrics:      defaultMetrics,
-					DatabasePath: defaultDbPath,
-					Network:      defaultNetwork, EthNode: defaultEthNode,
+					Verbosity:       defaultVerbosity,
+					RpcHost:         defaultRpcHost,
+					RpcPort:         defaultRpcPort,
+					Ws:              defaultWs,
+					WsPort:          defaultWsPort,
+					RpcTlsCert:      defaultRpcTlsCert,
+					RpcTlsKey:       defaultRpcTlsKey,
+					RpcCorsOrigins:  defaultRpcCorsOrigins,
+					RpcMaxBodySize:  defaultRpcMaxBodySize,
+					RpcMaxBatchSize: defaultRpcMaxBatchSize,
+					RpcRateLimit:    defaultRpcRateLimit,
+					RpcMaxInFlight:  defaultRpcMaxInFlight,
+					ReadyMaxLag:     defaultReadyMaxLag,
+					IpcPath:         defaultIpcPath,
+					Metrics:         defaultMetrics,
+					DatabasePath:    defaultDbPath,
+					Network:         defaultNetwork, EthNode: defaultEthNode,
+
+					SyncMaxLookahead:      defaultSyncMaxLookahead,
+					SyncMaxLookaheadBytes: defaultSyncMaxLookaheadBytes,
+					Sources:               defaultSources,
+					SourceQuorum:          defaultSourceQuorum,
+					RpcTimeout:            defaultRpcTimeout,
+					RpcMethodTimeouts:     defaultRpcMethodTimeouts,
 				},
 			},
 			"config file path is empty string": {
 				inputArgs: []string{"--config", ""},
 				expectedConfig: &node.Config{
-					Verbosity:    defaultVerbosity,
-					RpcPort:      defaultRpcPort,
-					Metrics:      defaultMetrics,
-					DatabasePath: defaultDbPath,
-					Network:      defaultNetwork, EthNode: defaultEthNode,
+					Verbosity:       defaultVerbosity,
+					RpcHost:         defaultRpcHost,
+					RpcPort:         defaultRpcPort,
+					Ws:              defaultWs,
+					WsPort:          defaultWsPort,
+					RpcTlsCert:      defaultRpcTlsCert,
+					RpcTlsKey:       defaultRpcTlsKey,
+					RpcCorsOrigins:  defaultRpcCorsOrigins,
+					RpcMaxBodySize:  defaultRpcMaxBodySize,
+					RpcMaxBatchSize: defaultRpcMaxBatchSize,
+					RpcRateLimit:    defaultRpcRateLimit,
+					RpcMaxInFlight:  defaultRpcMaxInFlight,
+					ReadyMaxLag:     defaultReadyMaxLag,
+					IpcPath:         defaultIpcPath,
+					Metrics:         defaultMetrics,
+					DatabasePath:    defaultDbPath,
+					Network:         defaultNetwork, EthNode: defaultEthNode,
+
+					SyncMaxLookahead:      defaultSyncMaxLookahead,
+					SyncMaxLookaheadBytes: defaultSyncMaxLookaheadBytes,
+					Sources:               defaultSources,
+					SourceQuorum:          defaultSourceQuorum,
+					RpcTimeout:            defaultRpcTimeout,
+					RpcMethodTimeouts:     defaultRpcMethodTimeouts,
 				},
 			},
 			"config file doesn't exist": {
@@ -108,28 +165,90 @@ Juno is a Go implementation of a Starknet full node client created by Nethermind
 				cfgFile:         tempCfgFile,
 				cfgFileContents: "\n",
 				expectedConfig: &node.Config{
-					Verbosity: defaultVerbosity,
-					RpcPort:   defaultRpcPort,
-					Metrics:   defaultMetrics,
-					Network:   defaultNetwork, EthNode: defaultEthNode,
+					Verbosity:       defaultVerbosity,
+					RpcHost:         defaultRpcHost,
+					RpcPort:         defaultRpcPort,
+					Ws:              defaultWs,
+					WsPort:          defaultWsPort,
+					RpcTlsCert:      defaultRpcTlsCert,
+					RpcTlsKey:       defaultRpcTlsKey,
+					RpcCorsOrigins:  defaultRpcCorsOrigins,
+					RpcMaxBodySize:  defaultRpcMaxBodySize,
+					RpcMaxBatchSize: defaultRpcMaxBatchSize,
+					RpcRateLimit:    defaultRpcRateLimit,
+					RpcMaxInFlight:  defaultRpcMaxInFlight,
+					ReadyMaxLag:     defaultReadyMaxLag,
+					IpcPath:         defaultIpcPath,
+					Metrics:         defaultMetrics,
+					Network:         defaultNetwork, EthNode: defaultEthNode,
+
+					SyncMaxLookahead:      defaultSyncMaxLookahead,
+					SyncMaxLookaheadBytes: defaultSyncMaxLookaheadBytes,
+					Sources:               defaultSources,
+					SourceQuorum:          defaultSourceQuorum,
+					RpcTimeout:            defaultRpcTimeout,
+					RpcMethodTimeouts:     defaultRpcMethodTimeouts,
 				},
 			},
 			"config file with all settings but without any other flags": {
 				cfgFile: tempCfgFile,
 				cfgFileContents: `verbosity: 0
+rpc-host: 127.0.0.1
 rpc-port: 4576
+ws: true
+ws-port: 4578
+rpc-tls-cert: /home/.juno/cert.pem
+rpc-tls-key: /home/.juno/key.pem
+rpc-cors-origins:
+  - https://a.example
+  - https://b.example
+rpc-max-body-size: 1024
+rpc-max-batch-size: 10
+rpc-rate-limit: 50
+rpc-max-in-flight: 64
+ready-max-lag: 3
+ipc-path: /home/.juno/juno.ipc
+sync-max-lookahead: 16
+sync-max-lookahead-bytes: 1048576
+source:
+  - /home/.juno/mainnet.zip
+  - https://mirror.example/feeder_gateway/
+source-quorum: 2
 metrics: true
 db-path: /home/.juno
 network: 2
 eth-node: "https://some-ethnode:5673"
+rpc-timeout: 10s
+rpc-method-timeouts:
+  starknet_getEvents: 1m
 `,
 				expectedConfig: &node.Config{
-					Verbosity:    utils.DEBUG,
-					RpcPort:      4576,
-					Metrics:      true,
-					DatabasePath: "/home/.juno",
-					Network:      utils.GOERLI2,
-					EthNode:      "https://some-ethnode:5673",
+					Verbosity:       utils.DEBUG,
+					RpcHost:         "127.0.0.1",
+					RpcPort:         4576,
+					Ws:              true,
+					WsPort:          4578,
+					RpcTlsCert:      "/home/.juno/cert.pem",
+					RpcTlsKey:       "/home/.juno/key.pem",
+					RpcCorsOrigins:  []string{"https://a.example", "https://b.example"},
+					RpcMaxBodySize:  1024,
+					RpcMaxBatchSize: 10,
+					RpcRateLimit:    50,
+					RpcMaxInFlight:  64,
+					ReadyMaxLag:     3,
+					IpcPath:         "/home/.juno/juno.ipc",
+					Metrics:         true,
+					DatabasePath:    "/home/.juno",
+					Network:         utils.GOERLI2,
+					EthNode:         "https://some-ethnode:5673",
+
+					SyncMaxLookahead:      16,
+					SyncMaxLookaheadBytes: 1048576,
+					Sources:               []string{"/home/.juno/mainnet.zip", "https://mirror.example/feeder_gateway/"},
+					SourceQuorum:          2,
+					RpcTimeout:            10 * time.Second,
+					// viper lowercases the keys of maps read from config files
+					RpcMethodTimeouts: map[string]time.Duration{"starknet_getevents": time.Minute},
 				},
 			},
 			"config file with some settings but without any other flags": {
@@ -139,27 +258,74 @@ rpc-port: 4576
 metrics: true
 `,
 				expectedConfig: &node.Config{
-					Verbosity:    utils.DEBUG,
-					RpcPort:      4576,
-					Metrics:      true,
-					DatabasePath: defaultDbPath,
-					Network:      defaultNetwork,
-					EthNode:      defaultEthNode,
+					Verbosity:       utils.DEBUG,
+					RpcHost:         defaultRpcHost,
+					RpcPort:         4576,
+					Ws:              defaultWs,
+					WsPort:          defaultWsPort,
+					RpcTlsCert:      defaultRpcTlsCert,
+					RpcTlsKey:       defaultRpcTlsKey,
+					RpcCorsOrigins:  defaultRpcCorsOrigins,
+					RpcMaxBodySize:  defaultRpcMaxBodySize,
+					RpcMaxBatchSize: defaultRpcMaxBatchSize,
+					RpcRateLimit:    defaultRpcRateLimit,
+					RpcMaxInFlight:  defaultRpcMaxInFlight,
+					ReadyMaxLag:     defaultReadyMaxLag,
+					IpcPath:         defaultIpcPath,
+					Metrics:         true,
+					DatabasePath:    defaultDbPath,
+					Network:         defaultNetwork,
+					EthNode:         defaultEthNode,
+
+					SyncMaxLookahead:      defaultSyncMaxLookahead,
+					SyncMaxLookaheadBytes: defaultSyncMaxLookaheadBytes,
+					Sources:               defaultSources,
+					SourceQuorum:          defaultSourceQuorum,
+					RpcTimeout:            defaultRpcTimeout,
+					RpcMethodTimeouts:     defaultRpcMethodTimeouts,
 				},
 			},
 			"all flags without config file": {
 				inputArgs: []string{
-					"--verbosity", "0", "--rpc-port", "4576",
+					"--verbosity", "0", "--rpc-host", "127.0.0.1", "--rpc-port", "4576", "--ws", "--ws-port", "4578",
+					"--rpc-tls-cert", "/home/.juno/cert.pem", "--rpc-tls-key", "/home/.juno/key.pem",
+					"--rpc-cors-origins", "https://a.example,https://b.example",
+					"--rpc-max-body-size", "1024", "--rpc-max-batch-size", "10",
+					"--rpc-rate-limit", "50", "--rpc-max-in-flight", "64", "--ready-max-lag", "3",
+					"--ipc-path", "/home/.juno/juno.ipc",
+					"--sync-max-lookahead", "16", "--sync-max-lookahead-bytes", "1048576",
+					"--source", "/home/.juno/mainnet.zip", "--source", "https://mirror.example/feeder_gateway/",
+					"--source-quorum", "2",
 					"--metrics", "--db-path", "/home/.juno", "--network", "1",
 					"--eth-node", "https://some-ethnode:5673",
+					"--rpc-timeout", "10s", "--rpc-method-timeouts", "starknet_getEvents=1m",
 				},
 				expectedConfig: &node.Config{
-					Verbosity:    utils.DEBUG,
-					RpcPort:      4576,
-					Metrics:      true,
-					DatabasePath: "/home/.juno",
-					Network:      utils.GOERLI,
-					EthNode:      "https://some-ethnode:5673",
+					Verbosity:       utils.DEBUG,
+					RpcHost:         "127.0.0.1",
+					RpcPort:         4576,
+					Ws:              true,
+					WsPort:          4578,
+					RpcTlsCert:      "/home/.juno/cert.pem",
+					RpcTlsKey:       "/home/.juno/key.pem",
+					RpcCorsOrigins:  []string{"https://a.example", "https://b.example"},
+					RpcMaxBodySize:  1024,
+					RpcMaxBatchSize: 10,
+					RpcRateLimit:    50,
+					RpcMaxInFlight:  64,
+					ReadyMaxLag:     3,
+					IpcPath:         "/home/.juno/juno.ipc",
+					Metrics:         true,
+					DatabasePath:    "/home/.juno",
+					Network:         utils.GOERLI,
+					EthNode:         "https://some-ethnode:5673",
+
+					SyncMaxLookahead:      16,
+					SyncMaxLookaheadBytes: 1048576,
+					Sources:               []string{"/home/.juno/mainnet.zip", "https://mirror.example/feeder_gateway/"},
+					SourceQuorum:          2,
+					RpcTimeout:            10 * time.Second,
+					RpcMethodTimeouts:     map[string]time.Duration{"starknet_getEvents": time.Minute},
 				},
 			},
 			"some flags without config file": {
@@ -168,12 +334,31 @@ metrics: true
 					"--network", "3",
 				},
 				expectedConfig: &node.Config{
-					Verbosity:    utils.DEBUG,
-					RpcPort:      4576,
-					Metrics:      defaultMetrics,
-					DatabasePath: "/home/.juno",
-					Network:      utils.INTEGRATION,
-					EthNode:      defaultEthNode,
+					Verbosity:       utils.DEBUG,
+					RpcHost:         defaultRpcHost,
+					RpcPort:         4576,
+					Ws:              defaultWs,
+					WsPort:          defaultWsPort,
+					RpcTlsCert:      defaultRpcTlsCert,
+					RpcTlsKey:       defaultRpcTlsKey,
+					RpcCorsOrigins:  defaultRpcCorsOrigins,
+					RpcMaxBodySize:  defaultRpcMaxBodySize,
+					RpcMaxBatchSize: defaultRpcMaxBatchSize,
+					RpcRateLimit:    defaultRpcRateLimit,
+					RpcMaxInFlight:  defaultRpcMaxInFlight,
+					ReadyMaxLag:     defaultReadyMaxLag,
+					IpcPath:         defaultIpcPath,
+					Metrics:         defaultMetrics,
+					DatabasePath:    "/home/.juno",
+					Network:         utils.INTEGRATION,
+					EthNode:         defaultEthNode,
+
+					SyncMaxLookahead:      defaultSyncMaxLookahead,
+					SyncMaxLookaheadBytes: defaultSyncMaxLookaheadBytes,
+					Sources:               defaultSources,
+					SourceQuorum:          defaultSourceQuorum,
+					RpcTimeout:            defaultRpcTimeout,
+					RpcMethodTimeouts:     defaultRpcMethodTimeouts,
 				},
 			},
 			"all setting set in both config file and flags": {
@@ -191,12 +376,31 @@ eth-node: "https://some-ethnode:5673"
 					"--eth-node", "https://some-ethnode:5674",
 				},
 				expectedConfig: &node.Config{
-					Verbosity:    utils.ERROR,
-					RpcPort:      4577,
-					Metrics:      true,
-					DatabasePath: "/home/flag/.juno",
-					Network:      utils.INTEGRATION,
-					EthNode:      "https://some-ethnode:5674",
+					Verbosity:       utils.ERROR,
+					RpcHost:         defaultRpcHost,
+					RpcPort:         4577,
+					Ws:              defaultWs,
+					WsPort:          defaultWsPort,
+					RpcTlsCert:      defaultRpcTlsCert,
+					RpcTlsKey:       defaultRpcTlsKey,
+					RpcCorsOrigins:  defaultRpcCorsOrigins,
+					RpcMaxBodySize:  defaultRpcMaxBodySize,
+					RpcMaxBatchSize: defaultRpcMaxBatchSize,
+					RpcRateLimit:    defaultRpcRateLimit,
+					RpcMaxInFlight:  defaultRpcMaxInFlight,
+					ReadyMaxLag:     defaultReadyMaxLag,
+					IpcPath:         defaultIpcPath,
+					Metrics:         true,
+					DatabasePath:    "/home/flag/.juno",
+					Network:         utils.INTEGRATION,
+					EthNode:         "https://some-ethnode:5674",
+
+					SyncMaxLookahead:      defaultSyncMaxLookahead,
+					SyncMaxLookaheadBytes: defaultSyncMaxLookaheadBytes,
+					Sources:               defaultSources,
+					SourceQuorum:          defaultSourceQuorum,
+					RpcTimeout:            defaultRpcTimeout,
+					RpcMethodTimeouts:     defaultRpcMethodTimeouts,
 				},
 			},
 			"some setting set in both config file and flags": {
@@ -210,12 +414,31 @@ network: 1
 					"https://some-ethnode:5674",
 				},
 				expectedConfig: &node.Config{
-					Verbosity:    utils.WARN,
-					RpcPort:      4576,
-					Metrics:      true,
-					DatabasePath: "/home/flag/.juno",
-					Network:      utils.GOERLI,
-					EthNode:      "https://some-ethnode:5674",
+					Verbosity:       utils.WARN,
+					RpcHost:         defaultRpcHost,
+					RpcPort:         4576,
+					Ws:              defaultWs,
+					WsPort:          defaultWsPort,
+					RpcTlsCert:      defaultRpcTlsCert,
+					RpcTlsKey:       defaultRpcTlsKey,
+					RpcCorsOrigins:  defaultRpcCorsOrigins,
+					RpcMaxBodySize:  defaultRpcMaxBodySize,
+					RpcMaxBatchSize: defaultRpcMaxBatchSize,
+					RpcRateLimit:    defaultRpcRateLimit,
+					RpcMaxInFlight:  defaultRpcMaxInFlight,
+					ReadyMaxLag:     defaultReadyMaxLag,
+					IpcPath:         defaultIpcPath,
+					Metrics:         true,
+					DatabasePath:    "/home/flag/.juno",
+					Network:         utils.GOERLI,
+					EthNode:         "https://some-ethnode:5674",
+
+					SyncMaxLookahead:      defaultSyncMaxLookahead,
+					SyncMaxLookaheadBytes: defaultSyncMaxLookaheadBytes,
+					Sources:               defaultSources,
+					SourceQuorum:          defaultSourceQuorum,
+					RpcTimeout:            defaultRpcTimeout,
+					RpcMethodTimeouts:     defaultRpcMethodTimeouts,
 				},
 			},
 			"some setting set in default, config file and flags": {
@@ -226,12 +449,31 @@ network: 1
 					"https://some-ethnode:5674",
 				},
 				expectedConfig: &node.Config{
-					Verbosity:    defaultVerbosity,
-					RpcPort:      defaultRpcPort,
-					Metrics:      true,
-					DatabasePath: "/home/flag/.juno",
-					Network:      utils.GOERLI2,
-					EthNode:      "https://some-ethnode:5674",
+					Verbosity:       defaultVerbosity,
+					RpcHost:         defaultRpcHost,
+					RpcPort:         defaultRpcPort,
+					Ws:              defaultWs,
+					WsPort:          defaultWsPort,
+					RpcTlsCert:      defaultRpcTlsCert,
+					RpcTlsKey:       defaultRpcTlsKey,
+					RpcCorsOrigins:  defaultRpcCorsOrigins,
+					RpcMaxBodySize:  defaultRpcMaxBodySize,
+					RpcMaxBatchSize: defaultRpcMaxBatchSize,
+					RpcRateLimit:    defaultRpcRateLimit,
+					RpcMaxInFlight:  defaultRpcMaxInFlight,
+					ReadyMaxLag:     defaultReadyMaxLag,
+					IpcPath:         defaultIpcPath,
+					Metrics:         true,
+					DatabasePath:    "/home/flag/.juno",
+					Network:         utils.GOERLI2,
+					EthNode:         "https://some-ethnode:5674",
+
+					SyncMaxLookahead:      defaultSyncMaxLookahead,
+					SyncMaxLookaheadBytes: defaultSyncMaxLookaheadBytes,
+					Sources:               defaultSources,
+					SourceQuorum:          defaultSourceQuorum,
+					RpcTimeout:            defaultRpcTimeout,
+					RpcMethodTimeouts:     defaultRpcMethodTimeouts,
 				},
 			},
 		}
@@ -36,6 +36,18 @@ type Block struct {
 	Receipts     []*TransactionReceipt
 }
 
+// L1Head is the latest Starknet block whose state update has been accepted on L1
+type L1Head struct {
+	// The number (height) of the block
+	BlockNumber uint64
+	// The hash of the block
+	BlockHash *felt.Felt
+	// The state commitment after the block
+	StateRoot *felt.Felt
+	// The number of the L1 block that includes the state update
+	L1BlockNumber uint64
+}
+
 type blockHashMetaInfo struct {
 	First07Block             uint64     // First block that uses the post-0.7.0 block hash algorithm
 	UnverifiableRange        []uint64   // Range of blocks that are not verifiable
@@ -20,6 +20,8 @@ type Class struct {
 	// The starknet_keccak hash of the ".json" file compiler output.
 	ProgramHash *felt.Felt
 	Bytecode    []*felt.Felt
+	// Base64 encoding of the gzip compressed ".json" file compiler output.
+	Program string
 }
 
 // EntryPoint uniquely identifies a Cairo function to execute.
@@ -135,6 +135,11 @@ func (z *Felt) Equal(x *Felt) bool {
 	return z.val.Equal(&x.val)
 }
 
+// Cmp forwards the call to underlying field element implementation
+func (z *Felt) Cmp(x *Felt) int {
+	return z.val.Cmp(&x.val)
+}
+
 // Marshal forwards the call to underlying field element implementation
 func (z *Felt) Marshal() []byte {
 	return z.val.Marshal()
@@ -0,0 +1,243 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+// ErrHistoryUnavailable is returned when the state of a block that is older than the
+// contract histories is requested.
+var ErrHistoryUnavailable = errors.New("state history is not available for the requested block")
+
+// historyKey builds the database key for a history entry. Histories are maintained as follows:
+//
+// [db.ContractStorageHistory](ContractAddress, StorageKey, ^BlockNumber) -> (Value)
+// [db.ContractNonceHistory](ContractAddress, ^BlockNumber) -> (Nonce)
+// [db.ContractClassHashHistory](ContractAddress, ^BlockNumber) -> (ClassHash)
+//
+// The block number is stored bitwise inverted so that seeking to the key of
+// a given block lands on the most recent change at or before that block.
+func historyKey(bucket db.Bucket, prefix []byte, blockNumber uint64) []byte {
+	return bucket.Key(prefix, binary.BigEndian.AppendUint64([]byte{}, ^blockNumber))
+}
+
+func storageHistoryPrefix(addr, key *felt.Felt) []byte {
+	return append(addr.Marshal(), key.Marshal()...)
+}
+
+// updateStorageHistory records the values set by diff at the given block number. It must be
+// called before diff is applied to the contract storage.
+func (s *State) updateStorageHistory(blockNumber uint64, addr *felt.Felt, diff []StorageDiff) error {
+	storage, err := NewContract(addr, s.txn).Storage()
+	if err != nil {
+		return err
+	}
+	for _, pair := range diff {
+		prefix := storageHistoryPrefix(addr, pair.Key)
+		storageKey := pair.Key
+		if err = s.recordValueAtStart(db.ContractStorageHistory, prefix, func() (*felt.Felt, error) {
+			return storage.Get(storageKey)
+		}); err != nil {
+			return err
+		}
+		if err = s.txn.Set(historyKey(db.ContractStorageHistory, prefix, blockNumber), pair.Value.Marshal()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateNonceHistory records the nonce of the contract at addr set at the given block number.
+// It must be called before the nonce is updated.
+func (s *State) updateNonceHistory(blockNumber uint64, addr, nonce *felt.Felt) error {
+	if err := s.recordValueAtStart(db.ContractNonceHistory, addr.Marshal(), NewContract(addr, s.txn).Nonce); err != nil {
+		return err
+	}
+	return s.txn.Set(historyKey(db.ContractNonceHistory, addr.Marshal(), blockNumber), nonce.Marshal())
+}
+
+// updateClassHashHistory records the class hash of the contract at addr set at the given block
+// number. It must be called before the contract is deployed.
+func (s *State) updateClassHashHistory(blockNumber uint64, addr, classHash *felt.Felt) error {
+	if err := s.recordValueAtStart(db.ContractClassHashHistory, addr.Marshal(), NewContract(addr, s.txn).ClassHash); err != nil {
+		return err
+	}
+	return s.txn.Set(historyKey(db.ContractClassHashHistory, addr.Marshal(), blockNumber), classHash.Marshal())
+}
+
+// historyStart returns the number of the oldest block whose state the contract histories
+// can reproduce. If no block was stored since histories are kept, [db.ErrKeyNotFound] is returned.
+func historyStart(txn db.Transaction) (start uint64, err error) {
+	err = txn.Get(db.HistoryStart.Key(), func(val []byte) error {
+		start = binary.BigEndian.Uint64(val)
+		return nil
+	})
+	return
+}
+
+// startHistory makes sure the contract histories can reproduce the state the block with
+// the given number is applied to. Databases that were synced before histories were kept
+// start them at the previous block without copying its state, which would take a single
+// transaction as large as the state. The values of that block are recorded as later blocks
+// overwrite them instead, values that never change are read from the current state.
+func (s *State) startHistory(blockNumber uint64) error {
+	if _, err := historyStart(s.txn); err == nil {
+		return nil
+	} else if !errors.Is(err, db.ErrKeyNotFound) {
+		return err
+	}
+
+	start := blockNumber
+	if blockNumber > 0 {
+		start = blockNumber - 1
+	}
+
+	startBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(startBytes, start)
+	return s.txn.Set(db.HistoryStart.Key(), startBytes)
+}
+
+// recordValueAtStart records the current value under prefix in the given history bucket as
+// its value at the start of the histories, unless the history already has an entry for prefix.
+// In databases synced before histories were kept the values at the start only live in the
+// current state, so they are recorded right before they are first overwritten. Values that
+// do not exist yet are not recorded.
+func (s *State) recordValueAtStart(bucket db.Bucket, prefix []byte, current func() (*felt.Felt, error)) error {
+	start, err := historyStart(s.txn)
+	if err != nil {
+		return err
+	}
+	if recorded, err := hasHistory(s.txn, bucket, prefix); err != nil || recorded {
+		return err
+	}
+
+	value, err := current()
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return s.txn.Set(historyKey(bucket, prefix, start), value.Marshal())
+}
+
+// hasHistory reports whether any value was recorded under prefix in the given history bucket.
+func hasHistory(txn db.Transaction, bucket db.Bucket, prefix []byte) (_ bool, err error) {
+	iterator, err := txn.NewIterator()
+	if err != nil {
+		return false, err
+	}
+	defer db.CloseAndWrapOnError(iterator.Close, &err)
+
+	key := bucket.Key(prefix)
+	return iterator.Seek(key) && bytes.HasPrefix(iterator.Key(), key), nil
+}
+
+// HistoricalState provides read access to the [State] as it was right after
+// the block with the given number was applied.
+type HistoricalState struct {
+	txn         db.Transaction
+	blockNumber uint64
+}
+
+func NewHistoricalState(txn db.Transaction, blockNumber uint64) *HistoricalState {
+	return &HistoricalState{
+		txn:         txn,
+		blockNumber: blockNumber,
+	}
+}
+
+// Class returns the class with the given class hash if it was declared at or before the
+// block of the HistoricalState, otherwise [db.ErrKeyNotFound] is returned. Classes stored
+// before declarations were recorded are returned for every block.
+func (h *HistoricalState) Class(classHash *felt.Felt) (*Class, error) {
+	declaredAt, err := classDeclarationBlock(h.txn, classHash)
+	if err == nil && declaredAt > h.blockNumber {
+		return nil, db.ErrKeyNotFound
+	} else if err != nil && !errors.Is(err, db.ErrKeyNotFound) {
+		return nil, err
+	}
+	return NewState(h.txn).GetClass(classHash)
+}
+
+// ContractClassHash returns the class hash of the contract at addr. If the
+// contract is not deployed, [db.ErrKeyNotFound] is returned.
+func (h *HistoricalState) ContractClassHash(addr *felt.Felt) (*felt.Felt, error) {
+	return h.valueOrCurrent(db.ContractClassHashHistory, addr.Marshal(), NewContract(addr, h.txn).ClassHash)
+}
+
+// ContractNonce returns the nonce of the contract at addr. If the contract
+// is not deployed, [db.ErrKeyNotFound] is returned.
+func (h *HistoricalState) ContractNonce(addr *felt.Felt) (*felt.Felt, error) {
+	return h.valueOrCurrent(db.ContractNonceHistory, addr.Marshal(), NewContract(addr, h.txn).Nonce)
+}
+
+// ContractStorage returns the value of the storage slot at key of the
+// contract at addr. Slots that were never written are zero. If the contract
+// is not deployed, [db.ErrKeyNotFound] is returned.
+func (h *HistoricalState) ContractStorage(addr, key *felt.Felt) (*felt.Felt, error) {
+	if _, err := h.ContractClassHash(addr); err != nil {
+		return nil, err
+	}
+
+	value, err := h.valueOrCurrent(db.ContractStorageHistory, storageHistoryPrefix(addr, key), func() (*felt.Felt, error) {
+		storage, err := NewContract(addr, h.txn).Storage()
+		if err != nil {
+			return nil, err
+		}
+		return storage.Get(key)
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return new(felt.Felt), nil
+	}
+	return value, err
+}
+
+// valueOrCurrent returns the value under prefix in the given history bucket at the block
+// number of the HistoricalState. Values that were never recorded have not changed since the
+// histories started, so they are read from the current state with current.
+func (h *HistoricalState) valueOrCurrent(bucket db.Bucket, prefix []byte,
+	current func() (*felt.Felt, error),
+) (*felt.Felt, error) {
+	value, err := h.valueAt(bucket, prefix)
+	if !errors.Is(err, db.ErrKeyNotFound) {
+		return value, err
+	}
+	if recorded, err := hasHistory(h.txn, bucket, prefix); err != nil {
+		return nil, err
+	} else if recorded {
+		return nil, db.ErrKeyNotFound
+	}
+	return current()
+}
+
+// valueAt returns the most recent value recorded under prefix in the given history
+// bucket at or before the block number of the HistoricalState. If the block is older than
+// the histories, [ErrHistoryUnavailable] is returned.
+func (h *HistoricalState) valueAt(bucket db.Bucket, prefix []byte) (value *felt.Felt, err error) {
+	if start, err := historyStart(h.txn); errors.Is(err, db.ErrKeyNotFound) || (err == nil && h.blockNumber < start) {
+		return nil, ErrHistoryUnavailable
+	} else if err != nil {
+		return nil, err
+	}
+
+	iterator, err := h.txn.NewIterator()
+	if err != nil {
+		return nil, err
+	}
+	defer db.CloseAndWrapOnError(iterator.Close, &err)
+
+	if !iterator.Seek(historyKey(bucket, prefix, h.blockNumber)) ||
+		!bytes.HasPrefix(iterator.Key(), bucket.Key(prefix)) {
+		return nil, db.ErrKeyNotFound
+	}
+
+	val, err := iterator.Value()
+	if err != nil {
+		return nil, err
+	}
+	return new(felt.Felt).SetBytes(val), nil
+}
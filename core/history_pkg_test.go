@@ -0,0 +1,188 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoricalState(t *testing.T) {
+	testDb := pebble.NewMemTest()
+	txn := testDb.NewTransaction(true)
+	state := NewState(txn)
+
+	addr, _ := new(felt.Felt).SetRandom()
+	classHash, _ := new(felt.Felt).SetRandom()
+	key, _ := new(felt.Felt).SetRandom()
+	otherKey, _ := new(felt.Felt).SetRandom()
+	value2 := new(felt.Felt).SetUint64(2)
+	value5 := new(felt.Felt).SetUint64(5)
+
+	t.Run("history has not started", func(t *testing.T) {
+		_, err := NewHistoricalState(txn, 0).ContractClassHash(addr)
+		assert.ErrorIs(t, err, ErrHistoryUnavailable)
+	})
+
+	require.NoError(t, state.startHistory(0))
+
+	t.Run("contract is not deployed", func(t *testing.T) {
+		historicalState := NewHistoricalState(txn, 0)
+		_, err := historicalState.ContractStorage(addr, key)
+		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
+		_, err = historicalState.ContractNonce(addr)
+		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
+		_, err = historicalState.ContractClassHash(addr)
+		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
+	})
+
+	require.NoError(t, state.updateClassHashHistory(1, addr, classHash))
+	require.NoError(t, state.updateNonceHistory(1, addr, &felt.Zero))
+	require.NoError(t, state.updateStorageHistory(2, addr, []StorageDiff{{Key: key, Value: value2}}))
+	require.NoError(t, state.updateNonceHistory(5, addr, value5))
+	require.NoError(t, state.updateStorageHistory(5, addr, []StorageDiff{
+		{Key: key, Value: value5},
+		{Key: otherKey, Value: value2},
+	}))
+
+	t.Run("contract is deployed later", func(t *testing.T) {
+		_, err := NewHistoricalState(txn, 0).ContractStorage(addr, key)
+		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
+	})
+
+	tests := map[uint64]struct {
+		value      *felt.Felt
+		otherValue *felt.Felt
+		nonce      *felt.Felt
+	}{
+		1: {value: &felt.Zero, otherValue: &felt.Zero, nonce: &felt.Zero},
+		2: {value: value2, otherValue: &felt.Zero, nonce: &felt.Zero},
+		4: {value: value2, otherValue: &felt.Zero, nonce: &felt.Zero},
+		5: {value: value5, otherValue: value2, nonce: value5},
+		9: {value: value5, otherValue: value2, nonce: value5},
+	}
+
+	for blockNumber, test := range tests {
+		historicalState := NewHistoricalState(txn, blockNumber)
+
+		got, err := historicalState.ContractStorage(addr, key)
+		require.NoError(t, err)
+		assert.Equal(t, test.value, got, "block %d", blockNumber)
+
+		got, err = historicalState.ContractStorage(addr, otherKey)
+		require.NoError(t, err)
+		assert.Equal(t, test.otherValue, got, "block %d", blockNumber)
+
+		got, err = historicalState.ContractNonce(addr)
+		require.NoError(t, err)
+		assert.Equal(t, test.nonce, got, "block %d", blockNumber)
+
+		got, err = historicalState.ContractClassHash(addr)
+		require.NoError(t, err)
+		assert.Equal(t, classHash, got, "block %d", blockNumber)
+	}
+}
+
+func TestStartHistory(t *testing.T) {
+	addr, _ := new(felt.Felt).SetRandom()
+	classHash, _ := new(felt.Felt).SetRandom()
+	key, _ := new(felt.Felt).SetRandom()
+	otherKey, _ := new(felt.Felt).SetRandom()
+	value2 := new(felt.Felt).SetUint64(2)
+	value5 := new(felt.Felt).SetUint64(5)
+
+	t.Run("empty database", func(t *testing.T) {
+		txn := pebble.NewMemTest().NewTransaction(true)
+		require.NoError(t, NewState(txn).startHistory(0))
+
+		start, err := historyStart(txn)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), start)
+	})
+
+	t.Run("database synced without histories", func(t *testing.T) {
+		txn := pebble.NewMemTest().NewTransaction(true)
+		state := NewState(txn)
+		require.NoError(t, state.putNewContract(addr, classHash))
+		require.NoError(t, state.updateContractNonce(addr, value5))
+		require.NoError(t, state.updateContractStorage(addr, []StorageDiff{
+			{Key: key, Value: value2},
+			{Key: otherKey, Value: value5},
+		}))
+
+		_, err := NewHistoricalState(txn, 9).ContractClassHash(addr)
+		assert.ErrorIs(t, err, ErrHistoryUnavailable)
+
+		require.NoError(t, state.startHistory(5))
+		start, err := historyStart(txn)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), start)
+
+		for _, blockNumber := range []uint64{4, 9} {
+			historicalState := NewHistoricalState(txn, blockNumber)
+
+			got, err := historicalState.ContractClassHash(addr)
+			require.NoError(t, err)
+			assert.Equal(t, classHash, got)
+
+			got, err = historicalState.ContractNonce(addr)
+			require.NoError(t, err)
+			assert.Equal(t, value5, got)
+
+			got, err = historicalState.ContractStorage(addr, key)
+			require.NoError(t, err)
+			assert.Equal(t, value2, got)
+
+			got, err = historicalState.ContractStorage(addr, otherKey)
+			require.NoError(t, err)
+			assert.Equal(t, value5, got)
+		}
+
+		_, err = NewHistoricalState(txn, 3).ContractStorage(addr, key)
+		assert.ErrorIs(t, err, ErrHistoryUnavailable)
+
+		t.Run("values are recorded before they are overwritten", func(t *testing.T) {
+			recorded, err := hasHistory(txn, db.ContractStorageHistory, storageHistoryPrefix(addr, key))
+			require.NoError(t, err)
+			assert.False(t, recorded)
+
+			require.NoError(t, state.updateNonceHistory(6, addr, value2))
+			require.NoError(t, state.updateContractNonce(addr, value2))
+			require.NoError(t, state.updateStorageHistory(6, addr, []StorageDiff{{Key: key, Value: value5}}))
+			require.NoError(t, state.updateContractStorage(addr, []StorageDiff{{Key: key, Value: value5}}))
+
+			tests := map[uint64]struct {
+				nonce *felt.Felt
+				value *felt.Felt
+			}{
+				4: {nonce: value5, value: value2},
+				5: {nonce: value5, value: value2},
+				6: {nonce: value2, value: value5},
+			}
+			for blockNumber, want := range tests {
+				historicalState := NewHistoricalState(txn, blockNumber)
+
+				got, err := historicalState.ContractNonce(addr)
+				require.NoError(t, err)
+				assert.Equal(t, want.nonce, got)
+
+				got, err = historicalState.ContractStorage(addr, key)
+				require.NoError(t, err)
+				assert.Equal(t, want.value, got)
+
+				got, err = historicalState.ContractStorage(addr, otherKey)
+				require.NoError(t, err)
+				assert.Equal(t, value5, got)
+			}
+		})
+		t.Run("history is started once", func(t *testing.T) {
+			require.NoError(t, state.startHistory(7))
+			start, err := historyStart(txn)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(4), start)
+		})
+	})
+}
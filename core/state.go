@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 
@@ -68,6 +69,61 @@ func (s *State) GetContractNonce(addr *felt.Felt) (*felt.Felt, error) {
 	return NewContract(addr, s.txn).Nonce()
 }
 
+// GetClass returns the class with the given class hash.
+func (s *State) GetClass(classHash *felt.Felt) (class *Class, err error) {
+	err = s.txn.Get(db.Class.Key(classHash.Marshal()), func(val []byte) error {
+		class = new(Class)
+		return encoder.Unmarshal(val, class)
+	})
+	return
+}
+
+// putClassDeclarationBlock records the number of the block that declared the class with
+// the given class hash.
+func (s *State) putClassDeclarationBlock(classHash *felt.Felt, blockNumber uint64) error {
+	numBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(numBytes, blockNumber)
+	return s.txn.Set(db.ClassDeclarationBlock.Key(classHash.Marshal()), numBytes)
+}
+
+// classDeclarationBlock returns the number of the block that declared the class with the
+// given class hash. Classes stored before declarations were recorded have no such number,
+// in which case [db.ErrKeyNotFound] is returned.
+func classDeclarationBlock(txn db.Transaction, classHash *felt.Felt) (blockNumber uint64, err error) {
+	err = txn.Get(db.ClassDeclarationBlock.Key(classHash.Marshal()), func(val []byte) error {
+		blockNumber = binary.BigEndian.Uint64(val)
+		return nil
+	})
+	return
+}
+
+// removeClassDeclaredAt deletes the class with the given class hash if it was declared by
+// the block with the given number.
+func (s *State) removeClassDeclaredAt(blockNumber uint64, classHash *felt.Felt) error {
+	declaredAt, err := classDeclarationBlock(s.txn, classHash)
+	if errors.Is(err, db.ErrKeyNotFound) || (err == nil && declaredAt != blockNumber) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err = s.txn.Delete(db.Class.Key(classHash.Marshal())); err != nil {
+		return err
+	}
+	return s.txn.Delete(db.ClassDeclarationBlock.Key(classHash.Marshal()))
+}
+
+// HasClass reports whether the class with the given class hash is stored.
+func (s *State) HasClass(classHash *felt.Felt) (bool, error) {
+	err := s.txn.Get(db.Class.Key(classHash.Marshal()), func(val []byte) error {
+		return nil
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // Root returns the state commitment.
 func (s *State) Root() (*felt.Felt, error) {
 	storage, err := s.getStateStorage()
@@ -119,8 +175,9 @@ func (s *State) putStateStorage(state *trie.Trie) error {
 // Update applies a StateUpdate to the State object. State is not
 // updated if an error is encountered during the operation. If update's
 // old or new root does not match the state's old or new roots,
-// [ErrMismatchedRoot] is returned.
-func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]*Class) error {
+// [ErrMismatchedRoot] is returned. Contract storage, nonce and class hash
+// changes are recorded in their histories under the given block number.
+func (s *State) Update(blockNumber uint64, update *StateUpdate, declaredClasses map[felt.Felt]*Class) error {
 	currentRoot, err := s.Root()
 	if err != nil {
 		return err
@@ -132,27 +189,40 @@ func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]*Class
 			IsOld: true,
 		}
 	}
+	if err = s.startHistory(blockNumber); err != nil {
+		return err
+	}
 
 	// register declared classes mentioned in stateDiff.deployedContracts and stateDiff.declaredClasses
 	for classHash, class := range declaredClasses {
-		classKey := db.Class.Key(classHash.Marshal())
-
-		if err := s.txn.Get(classKey, func(val []byte) error {
-			return nil
-		}); errors.Is(err, db.ErrKeyNotFound) {
-			classEncoded, err := encoder.Marshal(class)
-			if err != nil {
-				return err
-			}
+		classHash := classHash
+		if stored, err := s.HasClass(&classHash); err != nil {
+			return err
+		} else if stored {
+			continue
+		}
 
-			if err := s.txn.Set(classKey, classEncoded); err != nil {
-				return err
-			}
+		classEncoded, err := encoder.Marshal(class)
+		if err != nil {
+			return err
+		}
+		if err := s.txn.Set(db.Class.Key(classHash.Marshal()), classEncoded); err != nil {
+			return err
+		}
+		if err := s.putClassDeclarationBlock(&classHash, blockNumber); err != nil {
+			return err
 		}
 	}
 
-	// register deployed contracts
+	// register deployed contracts, histories are updated first as they may need the values
+	// that are about to be overwritten
 	for _, contract := range update.StateDiff.DeployedContracts {
+		if err := s.updateClassHashHistory(blockNumber, contract.Address, contract.ClassHash); err != nil {
+			return err
+		}
+		if err := s.updateNonceHistory(blockNumber, contract.Address, &felt.Zero); err != nil {
+			return err
+		}
 		if err := s.putNewContract(contract.Address, contract.ClassHash); err != nil {
 			return err
 		}
@@ -160,6 +230,9 @@ func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]*Class
 
 	// update contract nonces
 	for addr, nonce := range update.StateDiff.Nonces {
+		if err = s.updateNonceHistory(blockNumber, &addr, nonce); err != nil {
+			return err
+		}
 		if err = s.updateContractNonce(&addr, nonce); err != nil {
 			return err
 		}
@@ -170,6 +243,9 @@ func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]*Class
 		if err != nil {
 			return err
 		}
+		if err = s.updateStorageHistory(blockNumber, &addr, diff); err != nil {
+			return err
+		}
 		if err = s.updateContractStorage(&addr, diff); err != nil {
 			return err
 		}
@@ -233,3 +309,125 @@ func (s *State) updateContractCommitment(contract *Contract) error {
 		return s.putStateStorage(state)
 	}
 }
+
+// Revert undoes the StateUpdate of the block with the given number, which must be the
+// last update applied to the State. The values the update overwrote are recovered from
+// the contract histories and the history entries of the block are removed. Classes that
+// were first declared by the block are removed as well.
+func (s *State) Revert(blockNumber uint64, update *StateUpdate) error {
+	currentRoot, err := s.Root()
+	if err != nil {
+		return err
+	}
+	if !update.NewRoot.Equal(currentRoot) {
+		return &ErrMismatchedRoot{
+			Want:  update.NewRoot,
+			Got:   currentRoot,
+			IsOld: false,
+		}
+	}
+
+	// revert contract storages
+	for addr, diff := range update.StateDiff.StorageDiffs {
+		reverseDiff := make([]StorageDiff, 0, len(diff))
+		for _, pair := range diff {
+			oldValue, err := s.valueBefore(blockNumber, db.ContractStorageHistory, storageHistoryPrefix(&addr, pair.Key))
+			if err != nil {
+				return err
+			}
+			reverseDiff = append(reverseDiff, StorageDiff{Key: pair.Key, Value: oldValue})
+			if err = s.txn.Delete(historyKey(db.ContractStorageHistory,
+				storageHistoryPrefix(&addr, pair.Key), blockNumber)); err != nil {
+				return err
+			}
+		}
+		if err = s.updateContractStorage(&addr, reverseDiff); err != nil {
+			return err
+		}
+	}
+
+	// revert contract nonces
+	for addr := range update.StateDiff.Nonces {
+		oldNonce, err := s.valueBefore(blockNumber, db.ContractNonceHistory, addr.Marshal())
+		if err != nil {
+			return err
+		}
+		if err = s.updateContractNonce(&addr, oldNonce); err != nil {
+			return err
+		}
+		if err = s.txn.Delete(historyKey(db.ContractNonceHistory, addr.Marshal(), blockNumber)); err != nil {
+			return err
+		}
+	}
+
+	// remove deployed contracts
+	for _, contract := range update.StateDiff.DeployedContracts {
+		if err = s.removeContract(contract.Address); err != nil {
+			return err
+		}
+		if err = s.txn.Delete(historyKey(db.ContractClassHashHistory, contract.Address.Marshal(), blockNumber)); err != nil {
+			return err
+		}
+		if err = s.txn.Delete(historyKey(db.ContractNonceHistory, contract.Address.Marshal(), blockNumber)); err != nil {
+			return err
+		}
+	}
+
+	// remove classes declared by the block
+	classHashes := update.StateDiff.DeclaredClasses
+	for _, contract := range update.StateDiff.DeployedContracts {
+		classHashes = append(classHashes, contract.ClassHash)
+	}
+	for _, classHash := range classHashes {
+		if err = s.removeClassDeclaredAt(blockNumber, classHash); err != nil {
+			return err
+		}
+	}
+
+	oldRoot, err := s.Root()
+	if err != nil {
+		return err
+	}
+	if !update.OldRoot.Equal(oldRoot) {
+		return &ErrMismatchedRoot{
+			Want:  update.OldRoot,
+			Got:   oldRoot,
+			IsOld: true,
+		}
+	}
+	return nil
+}
+
+// valueBefore returns the most recent value recorded under prefix in the given history
+// bucket before the block with the given number. Values that were never recorded are zero.
+func (s *State) valueBefore(blockNumber uint64, bucket db.Bucket, prefix []byte) (*felt.Felt, error) {
+	if blockNumber == 0 {
+		return new(felt.Felt), nil
+	}
+
+	value, err := NewHistoricalState(s.txn, blockNumber-1).valueAt(bucket, prefix)
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return new(felt.Felt), nil
+	}
+	return value, err
+}
+
+// removeContract deletes the contract at the given address from the database and
+// from the global state Trie. Its storage must already be empty.
+func (s *State) removeContract(addr *felt.Felt) error {
+	addrBytes := addr.Marshal()
+	for _, bucket := range []db.Bucket{db.ContractClassHash, db.ContractNonce, db.ContractRootKey} {
+		if err := s.txn.Delete(bucket.Key(addrBytes)); err != nil {
+			return err
+		}
+	}
+
+	state, err := s.getStateStorage()
+	if err != nil {
+		return err
+	}
+	if _, err = state.Put(addr, &felt.Zero); err != nil {
+		return err
+	}
+	return s.putStateStorage(state)
+}
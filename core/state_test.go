@@ -7,8 +7,10 @@ import (
 	"github.com/NethermindEth/juno/clients"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUpdate(t *testing.T) {
@@ -169,7 +171,7 @@ func TestUpdate(t *testing.T) {
 	testDb := pebble.NewMemTest()
 	state := core.NewState(testDb.NewTransaction(true))
 
-	assert.Equal(t, nil, state.Update(coreUpdate, nil))
+	assert.Equal(t, nil, state.Update(0, coreUpdate, nil))
 }
 
 func TestUpdateNonce(t *testing.T) {
@@ -188,7 +190,7 @@ func TestUpdateNonce(t *testing.T) {
 	testDb := pebble.NewMemTest()
 	state := core.NewState(testDb.NewTransaction(true))
 
-	assert.NoError(t, state.Update(coreUpdate, nil))
+	assert.NoError(t, state.Update(0, coreUpdate, nil))
 
 	nonce, err := state.GetContractNonce(addr)
 	assert.NoError(t, err)
@@ -202,9 +204,129 @@ func TestUpdateNonce(t *testing.T) {
 
 	nonce.SetUint64(1)
 	coreUpdate.StateDiff.Nonces[*addr] = nonce
-	assert.NoError(t, state.Update(coreUpdate, nil))
+	assert.NoError(t, state.Update(1, coreUpdate, nil))
 
 	newNonce, err := state.GetContractNonce(addr)
 	assert.NoError(t, err)
 	assert.Equal(t, true, nonce.Equal(newNonce))
 }
+
+func TestRevert(t *testing.T) {
+	root0, _ := new(felt.Felt).SetString("0x4bdef7bf8b81a868aeab4b48ef952415fe105ab479e2f7bc671c92173542368")
+	root1, _ := new(felt.Felt).SetString("0x6210642ffd49f64617fc9e5c0bbe53a6a92769e2996eb312a42d2bdb7f2afc1")
+	addr, _ := new(felt.Felt).SetString("0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6")
+	classHash, _ := new(felt.Felt).SetString("0x10455c752b86932ce552f2b0fe81a880746649b9aee7e0d842bf3f52378f9f8")
+
+	update0 := &core.StateUpdate{
+		OldRoot: new(felt.Felt),
+		NewRoot: root0,
+		StateDiff: &core.StateDiff{
+			DeployedContracts: []core.DeployedContract{{Address: addr, ClassHash: classHash}},
+		},
+	}
+	update1 := &core.StateUpdate{
+		OldRoot: root0,
+		NewRoot: root1,
+		StateDiff: &core.StateDiff{
+			Nonces: map[felt.Felt]*felt.Felt{*addr: new(felt.Felt).SetUint64(1)},
+		},
+	}
+
+	txn := pebble.NewMemTest().NewTransaction(true)
+	state := core.NewState(txn)
+	require.NoError(t, state.Update(0, update0, nil))
+	require.NoError(t, state.Update(1, update1, nil))
+
+	t.Run("only the last update can be reverted", func(t *testing.T) {
+		assert.Error(t, state.Revert(0, update0))
+	})
+
+	t.Run("nonce is reverted", func(t *testing.T) {
+		require.NoError(t, state.Revert(1, update1))
+
+		root, err := state.Root()
+		require.NoError(t, err)
+		assert.Equal(t, root0, root)
+
+		nonce, err := state.GetContractNonce(addr)
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, nonce)
+	})
+
+	t.Run("deployed contract is removed", func(t *testing.T) {
+		require.NoError(t, state.Revert(0, update0))
+
+		root, err := state.Root()
+		require.NoError(t, err)
+		assert.Equal(t, new(felt.Felt), root)
+
+		_, err = state.GetContractClass(addr)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, err = core.NewHistoricalState(txn, 0).ContractClassHash(addr)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	t.Run("reverted updates can be applied again", func(t *testing.T) {
+		require.NoError(t, state.Update(0, update0, map[felt.Felt]*core.Class{*classHash: {}}))
+		require.NoError(t, state.Update(1, update1, nil))
+	})
+
+	t.Run("classes declared by the block are removed", func(t *testing.T) {
+		require.NoError(t, state.Revert(1, update1))
+		stored, err := state.HasClass(classHash)
+		require.NoError(t, err)
+		assert.True(t, stored)
+
+		require.NoError(t, state.Revert(0, update0))
+		stored, err = state.HasClass(classHash)
+		require.NoError(t, err)
+		assert.False(t, stored)
+	})
+}
+
+func TestGetClass(t *testing.T) {
+	classHash, _ := new(felt.Felt).SetRandom()
+	selector, _ := new(felt.Felt).SetRandom()
+	class := &core.Class{
+		Externals: []core.EntryPoint{{Selector: selector, Offset: new(felt.Felt).SetUint64(7)}},
+		Program:   "H4sIAAAAAAAA/w==",
+	}
+
+	coreUpdate := new(core.StateUpdate)
+	coreUpdate.OldRoot = new(felt.Felt)
+	coreUpdate.NewRoot = new(felt.Felt)
+	coreUpdate.StateDiff = new(core.StateDiff)
+
+	testDb := pebble.NewMemTest()
+	txn := testDb.NewTransaction(true)
+	state := core.NewState(txn)
+
+	_, err := state.GetClass(classHash)
+	assert.EqualError(t, err, db.ErrKeyNotFound.Error())
+	stored, err := state.HasClass(classHash)
+	assert.NoError(t, err)
+	assert.False(t, stored)
+
+	assert.NoError(t, state.Update(0, coreUpdate, map[felt.Felt]*core.Class{*classHash: class}))
+
+	stored, err = state.HasClass(classHash)
+	assert.NoError(t, err)
+	assert.True(t, stored)
+
+	got, err := state.GetClass(classHash)
+	assert.NoError(t, err)
+	assert.Equal(t, class.Program, got.Program)
+	assert.Equal(t, class.Externals, got.Externals)
+
+	t.Run("class is available from the block that declared it", func(t *testing.T) {
+		coreUpdate.StateDiff.DeclaredClasses = []*felt.Felt{classHash}
+		require.NoError(t, state.Revert(0, coreUpdate))
+
+		require.NoError(t, state.Update(3, coreUpdate, map[felt.Felt]*core.Class{*classHash: class}))
+		_, err := core.NewHistoricalState(txn, 2).Class(classHash)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		got, err := core.NewHistoricalState(txn, 3).Class(classHash)
+		require.NoError(t, err)
+		assert.Equal(t, class.Program, got.Program)
+	})
+}
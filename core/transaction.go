@@ -11,6 +11,7 @@ import (
 	"github.com/NethermindEth/juno/encoder"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/sha3"
 )
 
 var ErrUnknownTransaction = errors.New("unknown transaction")
@@ -226,6 +227,35 @@ func (l *L1HandlerTransaction) Signature() []*felt.Felt {
 	return make([]*felt.Felt, 0)
 }
 
+// MessageHash returns the hash of the L1 to L2 message the transaction consumes, which is how
+// the message is identified on L1. The first element of the calldata is the L1 sender and the
+// rest is the payload of the message.
+func (l *L1HandlerTransaction) MessageHash() []byte {
+	fromAddress, payload := new(felt.Felt), l.CallData
+	if len(payload) > 0 {
+		fromAddress, payload = payload[0], payload[1:]
+	}
+	nonce := l.Nonce
+	if nonce == nil {
+		// L1 handlers of early blocks do not report a nonce
+		nonce = new(felt.Felt)
+	}
+
+	words := []*felt.Felt{
+		fromAddress,
+		l.ContractAddress,
+		nonce,
+		l.EntryPointSelector,
+		new(felt.Felt).SetUint64(uint64(len(payload))),
+	}
+	digest := sha3.NewLegacyKeccak256()
+	for _, word := range append(words, payload...) {
+		bytes := word.Bytes()
+		digest.Write(bytes[:])
+	}
+	return digest.Sum(nil)
+}
+
 func transactionHash(transaction Transaction, n utils.Network) (*felt.Felt, error) {
 	switch t := transaction.(type) {
 	case *DeclareTransaction:
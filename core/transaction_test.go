@@ -1,11 +1,16 @@
 package core_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/encoder"
+	"github.com/NethermindEth/juno/testsource"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -130,3 +135,36 @@ func checkTransactionSymmetry(t *testing.T, input core.Transaction) {
 		t.Error("not a transaction")
 	}
 }
+
+func TestMessageHash(t *testing.T) {
+	gw, closeFn := testsource.NewTestGateway(utils.MAINNET)
+	defer closeFn()
+	block, err := gw.BlockByNumber(context.Background(), 1059)
+	require.NoError(t, err)
+
+	var handlers int
+	for i, txn := range block.Transactions {
+		l1Handler, ok := txn.(*core.L1HandlerTransaction)
+		if !ok {
+			continue
+		}
+		handlers++
+
+		// the hash of the message as the L1 contract computes it, from the consumed message
+		// that the receipt reports
+		message := block.Receipts[i].L1ToL2Message
+		require.NotNil(t, message)
+		words := [][]byte{
+			common.LeftPadBytes(message.From.Bytes(), 32),
+			message.To.Marshal(),
+			message.Nonce.Marshal(),
+			message.Selector.Marshal(),
+			common.LeftPadBytes([]byte{byte(len(message.Payload))}, 32),
+		}
+		for _, word := range message.Payload {
+			words = append(words, word.Marshal())
+		}
+		assert.Equal(t, crypto.Keccak256(words...), l1Handler.MessageHash())
+	}
+	assert.NotZero(t, handlers)
+}
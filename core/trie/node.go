@@ -1,7 +1,6 @@
 package trie
 
 import (
-	"encoding/binary"
 	"fmt"
 
 	"github.com/NethermindEth/juno/core/crypto"
@@ -30,18 +29,7 @@ func (n *Node) Hash(path *bitset.BitSet) *felt.Felt {
 		return n.Value
 	}
 
-	pathWords := path.Bytes()
-	if len(pathWords) > 4 {
-		panic("key too long to fit in Felt")
-	}
-
-	var pathBytes [32]byte
-	for idx, word := range pathWords {
-		startBytes := 24 - (idx * 8)
-		binary.BigEndian.PutUint64(pathBytes[startBytes:startBytes+8], word)
-	}
-
-	pathFelt := new(felt.Felt).SetBytes(pathBytes[:])
+	pathFelt := bitSetToFelt(path)
 
 	// https://docs.starknet.io/documentation/develop/State/starknet-state/
 	hash := crypto.Pedersen(n.Value, pathFelt)
@@ -2,6 +2,7 @@
 package trie
 
 import (
+	"encoding/binary"
 	"fmt"
 	"strings"
 
@@ -65,6 +66,21 @@ func (t *Trie) feltToBitSet(k *felt.Felt) *bitset.BitSet {
 	return bitset.FromWithLength(t.height, kBits[:])
 }
 
+// bitSetToFelt converts a key or path bitset back to the felt it encodes.
+func bitSetToFelt(k *bitset.BitSet) *felt.Felt {
+	kWords := k.Bytes()
+	if len(kWords) > 4 {
+		panic("key too long to fit in Felt")
+	}
+
+	var kBytes [32]byte
+	for idx, word := range kWords {
+		startBytes := 24 - (idx * 8)
+		binary.BigEndian.PutUint64(kBytes[startBytes:startBytes+8], word)
+	}
+	return new(felt.Felt).SetBytes(kBytes[:])
+}
+
 // findCommonKey finds the set of common MSB bits in two key bitsets.
 func findCommonKey(longerKey, shorterKey *bitset.BitSet) (*bitset.BitSet, bool) {
 	divergentBit := uint(0)
@@ -344,6 +360,35 @@ func (t *Trie) RootKey() *bitset.BitSet {
 	return t.rootKey
 }
 
+// Leaves calls do with the key and value of every leaf of the [Trie],
+// in ascending key order.
+func (t *Trie) Leaves(do func(key, value *felt.Felt) error) error {
+	if t.rootKey == nil {
+		return nil
+	}
+	return t.leaves(t.rootKey, do)
+}
+
+func (t *Trie) leaves(key *bitset.BitSet, do func(key, value *felt.Felt) error) error {
+	node, err := t.storage.Get(key)
+	if err != nil {
+		return err
+	}
+
+	if key.Len() == t.height {
+		return do(bitSetToFelt(key), node.Value)
+	}
+	for _, child := range []*bitset.BitSet{node.Left, node.Right} {
+		if child == nil {
+			continue
+		}
+		if err = t.leaves(child, do); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (t *Trie) Dump() {
 	t.dump(0, nil)
 }
@@ -684,3 +684,56 @@ func TestOldData(t *testing.T) {
 		return nil
 	})
 }
+
+func TestLeaves(t *testing.T) {
+	t.Run("empty trie", func(t *testing.T) {
+		require.NoError(t, RunOnTempTrie(251, func(trie *Trie) error {
+			return trie.Leaves(func(key, value *felt.Felt) error {
+				return fmt.Errorf("unexpected leaf %s", key)
+			})
+		}))
+	})
+
+	t.Run("all leaves in key order", func(t *testing.T) {
+		require.NoError(t, RunOnTempTrie(251, func(trie *Trie) error {
+			keys := []uint64{0x77, 0x2, 0x3, 0x5f0}
+			for _, key := range keys {
+				if _, err := trie.Put(new(felt.Felt).SetUint64(key), new(felt.Felt).SetUint64(key+1)); err != nil {
+					return err
+				}
+			}
+			// deleted keys are not reported
+			if _, err := trie.Put(new(felt.Felt).SetUint64(0x3), new(felt.Felt)); err != nil {
+				return err
+			}
+
+			var got []uint64
+			require.NoError(t, trie.Leaves(func(key, value *felt.Felt) error {
+				assert.Equal(t, new(felt.Felt).Add(key, new(felt.Felt).SetUint64(1)), value)
+				got = append(got, key.Bits()[0])
+				return nil
+			}))
+			assert.Equal(t, []uint64{0x2, 0x77, 0x5f0}, got)
+			return nil
+		}))
+	})
+
+	t.Run("error from callback stops the walk", func(t *testing.T) {
+		require.NoError(t, RunOnTempTrie(251, func(trie *Trie) error {
+			for _, key := range []uint64{1, 2} {
+				if _, err := trie.Put(new(felt.Felt).SetUint64(key), new(felt.Felt).SetUint64(key)); err != nil {
+					return err
+				}
+			}
+
+			calls := 0
+			err := trie.Leaves(func(key, value *felt.Felt) error {
+				calls++
+				return fmt.Errorf("stop")
+			})
+			assert.EqualError(t, err, "stop")
+			assert.Equal(t, 1, calls)
+			return nil
+		}))
+	})
+}
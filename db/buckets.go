@@ -22,6 +22,14 @@ const (
 	TransactionsByBlockNumberAndIndex       // maps block number and index to transaction
 	ReceiptsByBlockNumberAndIndex           // maps block number and index to transaction receipt
 	StateUpdatesByBlockNumber
+	ContractStorageHistory    // maps contract addresses, storage keys and block numbers to storage values
+	EventFiltersByBlockNumber // maps block numbers to bloom filters of the event addresses and keys in the block
+	ContractNonceHistory      // maps contract addresses and block numbers to contract nonces
+	ContractClassHashHistory  // maps contract addresses and block numbers to class hashes
+	L1Head                    // latest Starknet block accepted on L1
+	L1ScannedHeight           // latest L1 block whose logs have been processed
+	HistoryStart              // oldest block whose state the contract histories can reproduce
+	ClassDeclarationBlock     // maps class hashes to the number of the block that declared them
 )
 
 // Key flattens a prefix and series of byte arrays into a single []byte.
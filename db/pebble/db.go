@@ -58,6 +58,11 @@ func (db *DB) NewTransaction(update bool) db.Transaction {
 	return txn
 }
 
+// Metrics returns the statistics of the underlying pebble database
+func (db *DB) Metrics() *pebble.Metrics {
+	return db.pebble.Metrics()
+}
+
 // Close : see io.Closer.Close
 func (db *DB) Close() error {
 	return db.pebble.Close()
@@ -24,7 +24,10 @@ func initEncModes() {
 		panic(err)
 	}
 
-	decMode, err = cbor.DecOptions{}.DecModeWithTags(ts)
+	// decode maps into values of type any as map[string]any so that they can be marshalled to JSON
+	decMode, err = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+	}.DecModeWithTags(ts)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,65 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const corsMaxAge = 10 * time.Minute
+
+// cors lets browsers call the Http and open connections to the Websocket from the pages of
+// the allowed origins, see https://developer.mozilla.org/en-US/docs/Web/HTTP/CORS
+type cors struct {
+	anyOrigin bool
+	origins   map[string]struct{}
+}
+
+func newCors(origins []string) *cors {
+	c := &cors{origins: make(map[string]struct{})}
+	for _, origin := range origins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+		c.origins[origin] = struct{}{}
+	}
+	return c
+}
+
+// allowed returns true if pages of origin are allowed
+func (c *cors) allowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	_, allowed := c.origins[origin]
+	return allowed
+}
+
+// handle adds the CORS headers to the response to req. It returns true if req is a preflight
+// request, which it answers.
+func (c *cors) handle(writer http.ResponseWriter, req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	header := writer.Header()
+	header.Add("Vary", "Origin")
+
+	if c.anyOrigin {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else if _, allowed := c.origins[origin]; allowed {
+		header.Set("Access-Control-Allow-Origin", origin)
+	} else {
+		// without the headers the browser blocks the response
+		return false
+	}
+
+	if req.Method != http.MethodOptions || req.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+	header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	header.Set("Access-Control-Allow-Headers", "Content-Type")
+	header.Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+	writer.WriteHeader(http.StatusNoContent)
+	return true
+}
@@ -0,0 +1,39 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// acceptsGzip reports whether the client that sent req can decode gzip encoded responses
+func acceptsGzip(req *http.Request) bool {
+	for _, accepted := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(accepted, ";")
+		if strings.TrimSpace(coding) != "gzip" {
+			continue
+		}
+		// a quality of zero means "not acceptable"
+		params = strings.TrimSpace(params)
+		if strings.HasPrefix(params, "q=") {
+			quality, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			return err != nil || quality > 0
+		}
+		return true
+	}
+	return false
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,8 +1,11 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"net"
 	"net/http"
 	"time"
@@ -11,36 +14,143 @@ import (
 )
 
 type Http struct {
-	addr *net.TCPAddr
+	listener listener
 
-	rpc  *Server
+	rpc  *versions
 	http *http.Server
 	log  utils.Logger
+
+	maxBodySize int64
+	rateLimiter *rateLimiter
+	inFlight    chan struct{}
+
+	handlers map[string]http.Handler
+
+	cors        *cors
+	gzipMinSize int
 }
 
 func NewHttp(port uint16, methods []Method, log utils.Logger) *Http {
 	h := &Http{
-		rpc: NewServer(),
-		addr: &net.TCPAddr{
-			IP:   net.IPv4zero,
-			Port: int(port),
-		},
-		http: &http.Server{},
-		log:  log,
+		rpc:      newVersions(methods),
+		listener: newListener(port),
+		http:     &http.Server{},
+		log:      log,
+		handlers: make(map[string]http.Handler),
 	}
 	h.http.Handler = h
-	for _, method := range methods {
-		err := h.rpc.RegisterMethod(method)
-		if err != nil {
-			panic(err)
-		}
+	return h
+}
+
+// WithVersion serves version of the API on path with the given methods. Requests to any
+// other path are served the methods the Http was created with.
+func (h *Http) WithVersion(path, version string, methods []Method) *Http {
+	h.rpc.add(path, version, methods)
+	return h
+}
+
+// WithBatchConcurrency sets how many requests of a batch are executed at the same time
+func (h *Http) WithBatchConcurrency(concurrency int) *Http {
+	h.rpc.configure(func(s *Server) { s.WithBatchConcurrency(concurrency) })
+	return h
+}
+
+// WithMaxBatchSize sets the maximum number of requests in a batch, zero means no limit
+func (h *Http) WithMaxBatchSize(size int) *Http {
+	h.rpc.configure(func(s *Server) { s.WithMaxBatchSize(size) })
+	return h
+}
+
+// WithTimeout sets the deadline of every request, zero means no deadline
+func (h *Http) WithTimeout(timeout time.Duration) *Http {
+	h.rpc.configure(func(s *Server) { s.WithTimeout(timeout) })
+	return h
+}
+
+// WithMethodTimeout sets the deadline of the requests for the given method
+func (h *Http) WithMethodTimeout(method string, timeout time.Duration) *Http {
+	h.rpc.configure(func(s *Server) { s.WithMethodTimeout(method, timeout) })
+	return h
+}
+
+// WithMaxBodySize sets the maximum size in bytes of a request body. Larger requests are
+// rejected with [http.StatusRequestEntityTooLarge]. Zero means no limit.
+func (h *Http) WithMaxBodySize(size int64) *Http {
+	h.maxBodySize = size
+	return h
+}
+
+// WithRateLimit sets how many requests per second every client IP can make, every request of
+// a batch counts. Requests beyond the limit are rejected with [http.StatusTooManyRequests].
+// Zero means no limit.
+func (h *Http) WithRateLimit(requestsPerSecond uint) *Http {
+	h.rateLimiter = nil
+	if requestsPerSecond > 0 {
+		h.rateLimiter = newRateLimiter(requestsPerSecond)
+	}
+	return h
+}
+
+// WithMaxInFlight sets how many requests can be handled at the same time. Requests beyond
+// the limit are rejected with [http.StatusTooManyRequests]. Zero means no limit.
+func (h *Http) WithMaxInFlight(maxInFlight uint) *Http {
+	h.inFlight = nil
+	if maxInFlight > 0 {
+		h.inFlight = make(chan struct{}, maxInFlight)
+	}
+	return h
+}
+
+// WithDiscovery registers the rpc.discover method, see [Server.WithDiscovery]
+func (h *Http) WithDiscovery(info OpenRPCInfo) *Http {
+	h.rpc.configure(func(s *Server) { s.WithDiscovery(info) })
+	return h
+}
+
+// WithMiddleware appends middlewares to the chain that every request goes through
+func (h *Http) WithMiddleware(middlewares ...Middleware) *Http {
+	h.rpc.configure(func(s *Server) { s.WithMiddleware(middlewares...) })
+	return h
+}
+
+// WithHandler serves the requests to path with handler instead of handling them as JSON-RPC
+// requests. These requests are not subject to the limits of the Http.
+func (h *Http) WithHandler(path string, handler http.Handler) *Http {
+	h.handlers[path] = handler
+	return h
+}
+
+// WithHost sets the host or IP address to listen on, an empty host listens on all interfaces
+func (h *Http) WithHost(host string) *Http {
+	h.listener.host = host
+	return h
+}
+
+// WithTLS serves HTTPS with the certificate and private key in the given PEM files
+func (h *Http) WithTLS(certFile, keyFile string) *Http {
+	h.listener.certFile, h.listener.keyFile = certFile, keyFile
+	return h
+}
+
+// WithCORS lets browsers call the Http from pages of the given origins, "*" allows any origin
+func (h *Http) WithCORS(origins []string) *Http {
+	h.cors = nil
+	if len(origins) > 0 {
+		h.cors = newCors(origins)
 	}
 	return h
 }
 
+// WithGzip compresses the responses of at least minSize bytes for the clients that accept
+// gzip encoded responses. Zero disables compression.
+func (h *Http) WithGzip(minSize int) *Http {
+	h.gzipMinSize = minSize
+	return h
+}
+
 // Run starts to listen for HTTP requests
 func (h *Http) Run(ctx context.Context) error {
-	listener, listenErr := net.ListenTCP("tcp", h.addr)
+	listener, listenErr := h.listener.listen()
 	if listenErr != nil {
 		return listenErr
 	}
@@ -64,16 +174,75 @@ func (h *Http) Run(ctx context.Context) error {
 
 // ServeHTTP processes an incoming HTTP request
 func (h *Http) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	if h.cors != nil && h.cors.handle(writer, req) {
+		return
+	}
+	if handler, found := h.handlers[req.URL.Path]; found {
+		handler.ServeHTTP(writer, req)
+		return
+	}
 	if req.Method != "POST" {
 		writer.WriteHeader(http.StatusMethodNotAllowed)
 		req.Close = true
 		return
 	}
 
-	resp, err := h.rpc.HandleReader(req.Body)
+	if h.inFlight != nil {
+		select {
+		case h.inFlight <- struct{}{}:
+			defer func() { <-h.inFlight }()
+		default:
+			writer.Header().Set("Retry-After", "1")
+			writeError(writer, http.StatusTooManyRequests, errTooManyInFlight)
+			return
+		}
+	}
+
+	var body io.Reader = req.Body
+	if h.maxBodySize > 0 {
+		data, err := io.ReadAll(io.LimitReader(req.Body, h.maxBodySize+1))
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, &Error{Code: InvalidRequest, Message: err.Error()})
+			return
+		}
+		if int64(len(data)) > h.maxBodySize {
+			req.Close = true
+			writeError(writer, http.StatusRequestEntityTooLarge, &Error{Code: LimitExceeded, Message: "request body too large"})
+			return
+		}
+		body = bytes.NewReader(data)
+	}
+
+	// the batch has to be read to know how many requests it holds
+	rateLimited := false
+	var admit admitFunc
+	if h.rateLimiter != nil {
+		client := clientIP(req)
+		admit = func(requests int) *Error {
+			if h.rateLimiter.allow(client, requests) {
+				return nil
+			}
+			rateLimited = true
+			return errRateLimited
+		}
+	}
+
+	resp, err := h.rpc.server(req.URL.Path).handleReader(req.Context(), body, nil, nil, admit)
 	writer.Header().Set("Content-Type", "application/json")
+	if h.gzipMinSize > 0 {
+		writer.Header().Add("Vary", "Accept-Encoding")
+		if len(resp) >= h.gzipMinSize && acceptsGzip(req) {
+			if compressed, gzipErr := gzipBytes(resp); gzipErr == nil {
+				writer.Header().Set("Content-Encoding", "gzip")
+				resp = compressed
+			}
+		}
+	}
 	if err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
+	} else if rateLimited {
+		writer.Header().Set("Retry-After", "1")
+		writer.WriteHeader(http.StatusTooManyRequests)
 	} else {
 		writer.WriteHeader(http.StatusOK)
 	}
@@ -81,3 +250,31 @@ func (h *Http) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
 		writer.Write(resp)
 	}
 }
+
+var (
+	errRateLimited     = &Error{Code: LimitExceeded, Message: "rate limit exceeded"}
+	errTooManyInFlight = &Error{Code: LimitExceeded, Message: "too many requests in flight"}
+)
+
+// writeError answers a request that was rejected before it was handled
+func writeError(writer http.ResponseWriter, status int, rpcErr *Error) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if resp, err := errorResponse(rpcErr); err == nil {
+		writer.Write(resp)
+	}
+}
+
+// errorResponse is the response to a request that was rejected before it was parsed
+func errorResponse(rpcErr *Error) ([]byte, error) {
+	return json.Marshal(&response{Version: "2.0", Error: rpcErr})
+}
+
+// clientIP returns the IP address the request was sent from
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
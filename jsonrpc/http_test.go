@@ -0,0 +1,391 @@
+package jsonrpc_test
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpLimits(t *testing.T) {
+	release := make(chan struct{})
+	newHttp := func() *jsonrpc.Http {
+		return jsonrpc.NewHttp(0, []jsonrpc.Method{
+			{
+				"subtract",
+				[]jsonrpc.Parameter{{Name: "minuend"}, {Name: "subtrahend"}},
+				func(a, b int) (int, *jsonrpc.Error) {
+					return a - b, nil
+				},
+			},
+			{
+				"block",
+				[]jsonrpc.Parameter{},
+				func() (int, *jsonrpc.Error) {
+					<-release
+					return 0, nil
+				},
+			},
+		}, utils.NewNopZapLogger())
+	}
+	post := func(t *testing.T, url, body string) (int, string) {
+		resp, err := http.Post(url, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(respBody)
+	}
+	const subtract = `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`
+
+	t.Run("body size", func(t *testing.T) {
+		srv := httptest.NewServer(newHttp().WithMaxBodySize(int64(len(subtract))))
+		defer srv.Close()
+
+		status, body := post(t, srv.URL, subtract)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":19,"id":1}`, body)
+
+		status, body = post(t, srv.URL, subtract+" ")
+		assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32005,"message":"request body too large"},"id":null}`, body)
+	})
+
+	t.Run("batch size", func(t *testing.T) {
+		srv := httptest.NewServer(newHttp().WithMaxBatchSize(1))
+		defer srv.Close()
+
+		status, body := post(t, srv.URL, "["+subtract+","+subtract+"]")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"code":-32600`)
+	})
+
+	t.Run("requests per second", func(t *testing.T) {
+		srv := httptest.NewServer(newHttp().WithRateLimit(2))
+		defer srv.Close()
+
+		for i := 0; i < 2; i++ {
+			status, _ := post(t, srv.URL, subtract)
+			assert.Equal(t, http.StatusOK, status)
+		}
+		status, body := post(t, srv.URL, subtract)
+		assert.Equal(t, http.StatusTooManyRequests, status)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32005,"message":"rate limit exceeded"},"id":null}`, body)
+	})
+
+	t.Run("every request of a batch counts against the rate", func(t *testing.T) {
+		srv := httptest.NewServer(newHttp().WithRateLimit(2))
+		defer srv.Close()
+
+		status, _ := post(t, srv.URL, "["+subtract+","+subtract+"]")
+		assert.Equal(t, http.StatusOK, status)
+		status, _ = post(t, srv.URL, subtract)
+		assert.Equal(t, http.StatusTooManyRequests, status)
+	})
+
+	t.Run("requests in flight", func(t *testing.T) {
+		srv := httptest.NewServer(newHttp().WithMaxInFlight(1))
+		defer srv.Close()
+
+		blocked := make(chan int)
+		go func() {
+			resp, err := http.Post(srv.URL, "application/json",
+				strings.NewReader(`{"jsonrpc": "2.0", "method": "block", "id": 1}`))
+			if err != nil {
+				blocked <- 0
+				return
+			}
+			resp.Body.Close()
+			blocked <- resp.StatusCode
+		}()
+
+		require.Eventually(t, func() bool {
+			status, _ := post(t, srv.URL, subtract)
+			return status == http.StatusTooManyRequests
+		}, 5*time.Second, 10*time.Millisecond)
+
+		close(release)
+		assert.Equal(t, http.StatusOK, <-blocked)
+		status, _ := post(t, srv.URL, subtract)
+		assert.Equal(t, http.StatusOK, status)
+	})
+}
+
+func TestHttpVersions(t *testing.T) {
+	version := func(name string) []jsonrpc.Method {
+		return []jsonrpc.Method{{
+			"version",
+			nil,
+			func() (string, *jsonrpc.Error) {
+				return name, nil
+			},
+		}}
+	}
+	handler := jsonrpc.NewHttp(0, version("default"), utils.NewNopZapLogger()).
+		WithVersion("/v0_2", "0.2.0", version("v0_2")).
+		WithMaxBatchSize(1).
+		WithVersion("/v0_3/", "0.3.0", version("v0_3")).
+		WithDiscovery(jsonrpc.OpenRPCInfo{Title: "test", Version: "default"})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	post := func(t *testing.T, path, body string) string {
+		resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(respBody)
+	}
+
+	for path, want := range map[string]string{
+		"":        "default",
+		"/":       "default",
+		"/v0_1":   "default",
+		"/v0_2":   "v0_2",
+		"/v0_2/":  "v0_2",
+		"/v0_3":   "v0_3",
+		"/v0_3//": "v0_3",
+	} {
+		t.Run("path "+path, func(t *testing.T) {
+			assert.Equal(t, `{"jsonrpc":"2.0","result":"`+want+`","id":1}`,
+				post(t, path, `{"jsonrpc":"2.0","method":"version","id":1}`))
+		})
+	}
+
+	t.Run("options apply to all versions", func(t *testing.T) {
+		batch := `[{"jsonrpc":"2.0","method":"version","id":1},{"jsonrpc":"2.0","method":"version","id":2}]`
+		for _, path := range []string{"/", "/v0_2", "/v0_3"} {
+			assert.Contains(t, post(t, path, batch), "exceeds the limit of 1")
+		}
+	})
+
+	t.Run("discovery reports the version", func(t *testing.T) {
+		discover := `{"jsonrpc":"2.0","method":"rpc.discover","id":1}`
+		assert.Contains(t, post(t, "/", discover), `"version":"default"`)
+		assert.Contains(t, post(t, "/v0_2", discover), `"version":"0.2.0"`)
+		assert.Contains(t, post(t, "/v0_3", discover), `"version":"0.3.0"`)
+	})
+}
+
+func TestHttpHandlers(t *testing.T) {
+	handler := jsonrpc.NewHttp(0, []jsonrpc.Method{}, utils.NewNopZapLogger()).
+		WithRateLimit(1).
+		WithHandler("/health", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	for i := 0; i < 3; i++ {
+		resp, err := http.Get(srv.URL + "/health")
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "ok", string(body))
+	}
+
+	resp, err := http.Get(srv.URL + "/ready")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestHttpCORS(t *testing.T) {
+	newServer := func(origins ...string) *httptest.Server {
+		return httptest.NewServer(jsonrpc.NewHttp(0, []jsonrpc.Method{}, utils.NewNopZapLogger()).WithCORS(origins))
+	}
+	send := func(t *testing.T, method, url, origin string) *http.Response {
+		req, err := http.NewRequest(method, url, strings.NewReader(`{"jsonrpc":"2.0","method":"none","id":1}`))
+		require.NoError(t, err)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp
+	}
+
+	t.Run("allowed origins", func(t *testing.T) {
+		srv := newServer("https://a.example", "https://b.example")
+		defer srv.Close()
+
+		resp := send(t, http.MethodPost, srv.URL, "https://b.example")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "https://b.example", resp.Header.Get("Access-Control-Allow-Origin"))
+
+		resp = send(t, http.MethodOptions, srv.URL, "https://a.example")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "https://a.example", resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, resp.Header.Get("Access-Control-Allow-Methods"), http.MethodPost)
+
+		resp = send(t, http.MethodPost, srv.URL, "https://c.example")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+		resp = send(t, http.MethodOptions, srv.URL, "https://c.example")
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("any origin", func(t *testing.T) {
+		srv := newServer("*")
+		defer srv.Close()
+
+		resp := send(t, http.MethodPost, srv.URL, "https://c.example")
+		assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+		resp = send(t, http.MethodPost, srv.URL, "")
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv := newServer()
+		defer srv.Close()
+
+		resp := send(t, http.MethodOptions, srv.URL, "https://a.example")
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestHttpGzip(t *testing.T) {
+	handler := jsonrpc.NewHttp(0, []jsonrpc.Method{{
+		"echo",
+		[]jsonrpc.Parameter{{Name: "msg"}},
+		func(msg string) (string, *jsonrpc.Error) {
+			return msg, nil
+		},
+	}}, utils.NewNopZapLogger()).WithGzip(100)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	echo := func(t *testing.T, msg, acceptEncoding string) (string, string) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL,
+			strings.NewReader(`{"jsonrpc":"2.0","method":"echo","params":["`+msg+`"],"id":1}`))
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body io.Reader = resp.Body
+		if resp.Header.Get("Content-Encoding") == "gzip" {
+			body, err = gzip.NewReader(resp.Body)
+			require.NoError(t, err)
+		}
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		return resp.Header.Get("Content-Encoding"), string(data)
+	}
+
+	long := strings.Repeat("a", 100)
+	for name, test := range map[string]struct {
+		msg            string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		"large response":               {long, "gzip", "gzip"},
+		"large response, many codings": {long, "br, gzip;q=0.5", "gzip"},
+		"small response":               {"a", "gzip", ""},
+		"gzip not accepted":            {long, "br", ""},
+		"gzip refused":                 {long, "gzip;q=0", ""},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			encoding, body := echo(t, test.msg, test.acceptEncoding)
+			assert.Equal(t, test.wantEncoding, encoding)
+			assert.Equal(t, `{"jsonrpc":"2.0","result":"`+test.msg+`","id":1}`, body)
+		})
+	}
+}
+
+func TestHttpTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPool := writeTestCertificate(t, certFile, keyFile)
+
+	// reserve a free port for the server
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := jsonrpc.NewHttp(uint16(port), []jsonrpc.Method{}, utils.NewNopZapLogger()).
+		WithHost("127.0.0.1").
+		WithTLS(certFile, keyFile).
+		WithHandler("/health", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+	require.NoError(t, handler.Run(ctx))
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: certPool}}}
+	resp, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d/health", port))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "ok", string(body))
+
+	t.Run("bad certificate", func(t *testing.T) {
+		badHandler := jsonrpc.NewHttp(0, []jsonrpc.Method{}, utils.NewNopZapLogger()).
+			WithTLS(filepath.Join(dir, "missing.pem"), keyFile)
+		assert.Error(t, badHandler.Run(ctx))
+	})
+}
+
+// writeTestCertificate writes a self-signed certificate for 127.0.0.1 and its key to the
+// given files and returns a pool that trusts it
+func writeTestCertificate(t *testing.T, certFile, keyFile string) *x509.CertPool {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "juno test"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return pool
+}
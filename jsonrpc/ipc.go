@@ -0,0 +1,241 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	stdsync "sync"
+	"time"
+
+	"github.com/NethermindEth/juno/utils"
+)
+
+const (
+	defaultMaxIpcMessageSize = 10 * 1024 * 1024 // 10 MiB
+	ipcWriteTimeout          = 10 * time.Second
+)
+
+// Ipc serves the registered Methods over a Unix domain socket. Every line received on a
+// connection is handled as a request or batch of requests and the response, if any, is sent
+// back on the same connection followed by a newline.
+//
+// Only the user running the node can connect to the socket, which makes it a trusted
+// channel for local tools.
+type Ipc struct {
+	path string
+
+	rpc *Server
+	log utils.Logger
+
+	maxMessageSize int
+
+	connsLock stdsync.Mutex
+	conns     map[net.Conn]struct{}
+	connsWg   stdsync.WaitGroup
+	closed    bool
+}
+
+func NewIpc(path string, methods []Method, log utils.Logger) *Ipc {
+	return &Ipc{
+		path:           path,
+		rpc:            newServerWithMethods(methods),
+		log:            log,
+		maxMessageSize: defaultMaxIpcMessageSize,
+		conns:          make(map[net.Conn]struct{}),
+	}
+}
+
+// WithSubscriptions lets clients subscribe to the given topics through the given methods
+func (i *Ipc) WithSubscriptions(methods SubscriptionMethods, topics []Topic) *Ipc {
+	if err := i.rpc.RegisterSubscriptions(methods, topics); err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// WithMaxBatchSize sets the maximum number of requests in a batch, zero means no limit
+func (i *Ipc) WithMaxBatchSize(size int) *Ipc {
+	i.rpc.WithMaxBatchSize(size)
+	return i
+}
+
+// WithTimeout sets the deadline of every request, zero means no deadline
+func (i *Ipc) WithTimeout(timeout time.Duration) *Ipc {
+	i.rpc.WithTimeout(timeout)
+	return i
+}
+
+// WithMethodTimeout sets the deadline of the requests for the given method
+func (i *Ipc) WithMethodTimeout(method string, timeout time.Duration) *Ipc {
+	i.rpc.WithMethodTimeout(method, timeout)
+	return i
+}
+
+// WithDiscovery registers the rpc.discover method, see [Server.WithDiscovery]
+func (i *Ipc) WithDiscovery(info OpenRPCInfo) *Ipc {
+	i.rpc.WithDiscovery(info)
+	return i
+}
+
+// WithMiddleware appends middlewares to the chain that every request goes through
+func (i *Ipc) WithMiddleware(middlewares ...Middleware) *Ipc {
+	i.rpc.WithMiddleware(middlewares...)
+	return i
+}
+
+// WithMaxMessageSize sets the maximum size in bytes of a line read from a connection.
+// Connections that send a longer line are closed.
+func (i *Ipc) WithMaxMessageSize(size int) *Ipc {
+	i.maxMessageSize = size
+	return i
+}
+
+// Run creates the socket and starts to accept connections on it. Once ctx is cancelled the
+// socket is removed and the open connections are closed.
+func (i *Ipc) Run(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(i.path), 0o700); err != nil {
+		return err
+	}
+	if err := removeStaleSocket(i.path); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("unix", i.path)
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(i.path, 0o600); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go i.accept(ctx, listener)
+	go func() {
+		<-ctx.Done()
+		if err := listener.Close(); err != nil {
+			i.log.Warnw("Error closing the IPC socket", "err", err)
+		}
+		i.closeConnections()
+	}()
+	return nil
+}
+
+// removeStaleSocket removes the socket left behind by a node that did not shut down cleanly
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return errors.New("IPC path exists and is not a socket: " + path)
+	}
+	return os.Remove(path)
+}
+
+func (i *Ipc) accept(ctx context.Context, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				i.log.Warnw("IPC listener stopped", "err", err)
+			}
+			return
+		}
+		if !i.trackConnection(conn) {
+			conn.Close()
+			return
+		}
+		go func() {
+			defer i.releaseConnection(conn)
+			i.serveConnection(ctx, conn)
+		}()
+	}
+}
+
+// ipcConn is an IPC connection that responses and notifications can be written to
+// concurrently
+type ipcConn struct {
+	conn      net.Conn
+	writeLock stdsync.Mutex
+	done      chan struct{}
+}
+
+func (c *ipcConn) Write(msg []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(ipcWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(append(msg, '\n'))
+	return err
+}
+
+func (c *ipcConn) Done() <-chan struct{} {
+	return c.done
+}
+
+func (i *Ipc) serveConnection(ctx context.Context, conn net.Conn) {
+	ic := &ipcConn{conn: conn, done: make(chan struct{})}
+	defer func() {
+		close(ic.done)
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), i.maxMessageSize)
+	for scanner.Scan() {
+		msg := bytes.TrimSpace(scanner.Bytes())
+		if len(msg) == 0 {
+			continue
+		}
+		if err := i.rpc.HandleConn(ctx, ic, msg); err != nil {
+			i.log.Debugw("Failed handling IPC request", "err", err)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		i.log.Debugw("IPC connection closed", "err", err)
+	}
+}
+
+// trackConnection registers conn so that it is closed on shutdown. It returns false if the
+// Ipc is shutting down.
+func (i *Ipc) trackConnection(conn net.Conn) bool {
+	i.connsLock.Lock()
+	defer i.connsLock.Unlock()
+
+	if i.closed {
+		return false
+	}
+	i.conns[conn] = struct{}{}
+	i.connsWg.Add(1)
+	return true
+}
+
+func (i *Ipc) releaseConnection(conn net.Conn) {
+	i.connsLock.Lock()
+	defer i.connsLock.Unlock()
+
+	delete(i.conns, conn)
+	i.connsWg.Done()
+}
+
+// closeConnections closes all open connections and waits for their handlers to return
+func (i *Ipc) closeConnections() {
+	i.connsLock.Lock()
+	i.closed = true
+	for conn := range i.conns {
+		conn.Close()
+	}
+	i.connsLock.Unlock()
+
+	i.connsWg.Wait()
+}
@@ -0,0 +1,101 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIpc(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "juno.ipc")
+	ipc := jsonrpc.NewIpc(path, []jsonrpc.Method{{
+		"subtract",
+		[]jsonrpc.Parameter{{Name: "minuend"}, {Name: "subtrahend"}},
+		func(a, b int) (int, *jsonrpc.Error) {
+			return a - b, nil
+		},
+	}}, utils.NewNopZapLogger()).WithSubscriptions(jsonrpc.SubscriptionMethods{
+		Subscribe:    "subscribe",
+		Unsubscribe:  "unsubscribe",
+		Notification: "subscription",
+	}, []jsonrpc.Topic{{
+		Name: "once",
+		Handler: func(sub *jsonrpc.Subscription) *jsonrpc.Error {
+			go sub.Notify("hello")
+			return nil
+		},
+	}})
+
+	// a socket left behind by a previous run is replaced
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, ipc.Run(ctx))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	lines := bufio.NewScanner(conn)
+	write := func(t *testing.T, msg string) {
+		_, err := conn.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+	read := func(t *testing.T) string {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		require.True(t, lines.Scan(), lines.Err())
+		return lines.Text()
+	}
+
+	t.Run("one response per line", func(t *testing.T) {
+		write(t, `{"jsonrpc":"2.0","method":"subtract","params":[42,23],"id":1}`+"\n\n"+
+			`{"jsonrpc":"2.0","method":"subtract","params":[23,42],"id":2}`+"\n")
+		assert.Equal(t, `{"jsonrpc":"2.0","result":19,"id":1}`, read(t))
+		assert.Equal(t, `{"jsonrpc":"2.0","result":-19,"id":2}`, read(t))
+	})
+
+	t.Run("requests can be split across writes", func(t *testing.T) {
+		write(t, `[{"jsonrpc":"2.0","method":"subtract",`)
+		write(t, `"params":[1,1],"id":3}]`+"\n")
+		assert.Equal(t, `[{"jsonrpc":"2.0","result":0,"id":3}]`, read(t))
+	})
+
+	t.Run("subscriptions", func(t *testing.T) {
+		write(t, `{"jsonrpc":"2.0","method":"subscribe","params":["once"],"id":4}`+"\n")
+		assert.Equal(t, `{"jsonrpc":"2.0","result":1,"id":4}`, read(t))
+		assert.Equal(t, `{"jsonrpc":"2.0","method":"subscription","params":{"subscription":1,"result":"hello"}}`, read(t))
+	})
+
+	t.Run("shutdown closes connections and removes the socket", func(t *testing.T) {
+		cancel()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		assert.False(t, lines.Scan())
+		assert.NoError(t, lines.Err())
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return os.IsNotExist(err)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("path that is not a socket", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+		assert.Error(t, jsonrpc.NewIpc(file, nil, utils.NewNopZapLogger()).Run(context.Background()))
+	})
+}
@@ -0,0 +1,37 @@
+package jsonrpc
+
+import (
+	"crypto/tls"
+	"net"
+	"strconv"
+)
+
+// listener is the network address that a transport listens on and, if TLS is enabled, the
+// files of the certificate it presents
+type listener struct {
+	host     string
+	port     uint16
+	certFile string
+	keyFile  string
+}
+
+func newListener(port uint16) listener {
+	return listener{host: net.IPv4zero.String(), port: port}
+}
+
+func (l *listener) listen() (net.Listener, error) {
+	addr := net.JoinHostPort(l.host, strconv.Itoa(int(l.port)))
+	if l.certFile == "" && l.keyFile == "" {
+		return net.Listen("tcp", addr)
+	}
+
+	// load the certificate first so that a bad one fails without binding the port
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return tls.Listen("tcp", addr, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+}
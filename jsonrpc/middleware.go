@@ -0,0 +1,60 @@
+package jsonrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/NethermindEth/juno/utils"
+)
+
+// Call is a request that is being handled by the Server
+type Call struct {
+	Method string
+	// Params are the parameters as they were received: nil, a []any or a map[string]any
+	Params any
+}
+
+// CallHandler executes a Call and returns its result or error
+type CallHandler func(ctx context.Context, call *Call) (any, *Error)
+
+// Middleware intercepts every request handled by the Server. It should call next to continue
+// with the rest of the chain, or return without calling it to reject the request.
+type Middleware func(ctx context.Context, call *Call, next CallHandler) (any, *Error)
+
+// WithMiddleware appends middlewares to the chain of the Server. The first middleware is the
+// outermost, it is called first and sees the results last.
+func (s *Server) WithMiddleware(middlewares ...Middleware) *Server {
+	s.middlewares = append(s.middlewares, middlewares...)
+	return s
+}
+
+func (s *Server) withMiddlewares(handler CallHandler) CallHandler {
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		middleware, next := s.middlewares[i], handler
+		handler = func(ctx context.Context, call *Call) (any, *Error) {
+			return middleware(ctx, call, next)
+		}
+	}
+	return handler
+}
+
+// LogRequests returns a Middleware that logs the method, duration and error of every request
+func LogRequests(log utils.SimpleLogger) Middleware {
+	return func(ctx context.Context, call *Call, next CallHandler) (any, *Error) {
+		start := time.Now()
+		result, err := next(ctx, call)
+		took := time.Since(start)
+
+		switch {
+		case err == nil:
+			log.Debugw("Handled RPC request", "method", call.Method, "took", took)
+		case err.Code == InternalError:
+			log.Warnw("Failed handling RPC request", "method", call.Method, "took", took,
+				"code", err.Code, "err", err.Message)
+		default:
+			log.Debugw("Rejected RPC request", "method", call.Method, "took", took,
+				"code", err.Code, "err", err.Message)
+		}
+		return result, err
+	}
+}
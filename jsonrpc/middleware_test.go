@@ -0,0 +1,85 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	var trace []string
+	tracing := func(name string) jsonrpc.Middleware {
+		return func(ctx context.Context, call *jsonrpc.Call, next jsonrpc.CallHandler) (any, *jsonrpc.Error) {
+			trace = append(trace, name+" before "+call.Method)
+			result, err := next(ctx, call)
+			trace = append(trace, name+" after")
+			return result, err
+		}
+	}
+
+	var seen []any
+	server := jsonrpc.NewServer().WithMiddleware(tracing("outer"), tracing("inner")).WithMiddleware(
+		func(ctx context.Context, call *jsonrpc.Call, next jsonrpc.CallHandler) (any, *jsonrpc.Error) {
+			if call.Method == "forbidden" {
+				return nil, &jsonrpc.Error{Code: 1, Message: "forbidden"}
+			}
+			if call.Method == "alias" {
+				call.Method = "subtract"
+			}
+			result, err := next(ctx, call)
+			seen = append(seen, call.Params, result, err)
+			return result, err
+		})
+	require.NoError(t, server.RegisterMethod(jsonrpc.Method{
+		"subtract",
+		[]jsonrpc.Parameter{{Name: "minuend"}, {Name: "subtrahend"}},
+		func(a, b int) (int, *jsonrpc.Error) {
+			return a - b, nil
+		},
+	}))
+
+	t.Run("middlewares are called in order", func(t *testing.T) {
+		res, err := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":19,"id":1}`, string(res))
+		assert.Equal(t, []string{"outer before subtract", "inner before subtract", "inner after", "outer after"}, trace)
+		require.Len(t, seen, 3)
+		assert.Len(t, seen[0], 2)
+		assert.Equal(t, 19, seen[1])
+		assert.Nil(t, seen[2])
+	})
+
+	t.Run("middlewares see errors", func(t *testing.T) {
+		seen = nil
+		res, err := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "add", "id": 2}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"method not found"},"id":2}`, string(res))
+		require.Len(t, seen, 3)
+		assert.Equal(t, &jsonrpc.Error{Code: jsonrpc.MethodNotFound, Message: "method not found"}, seen[2])
+	})
+
+	t.Run("middlewares can reject requests", func(t *testing.T) {
+		seen = nil
+		res, err := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "forbidden", "id": 3}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":1,"message":"forbidden"},"id":3}`, string(res))
+		assert.Empty(t, seen)
+	})
+
+	t.Run("middlewares can rewrite requests", func(t *testing.T) {
+		res, err := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "alias", "params": [2, 1], "id": 4}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":1,"id":4}`, string(res))
+	})
+
+	t.Run("notifications go through the chain", func(t *testing.T) {
+		trace = nil
+		res, err := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23]}`))
+		require.NoError(t, err)
+		assert.Nil(t, res)
+		assert.Len(t, trace, 4)
+	})
+}
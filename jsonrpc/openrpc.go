@@ -0,0 +1,207 @@
+package jsonrpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	openRPCVersion = "1.2.6"
+	discoverMethod = "rpc.discover"
+)
+
+// OpenRPC is a service description as specified by https://spec.open-rpc.org
+type OpenRPC struct {
+	OpenRPC    string           `json:"openrpc"`
+	Info       OpenRPCInfo      `json:"info"`
+	Methods    []*OpenRPCMethod `json:"methods"`
+	Components *Components      `json:"components,omitempty"`
+}
+
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenRPCMethod struct {
+	Name   string               `json:"name"`
+	Params []*ContentDescriptor `json:"params"`
+	Result *ContentDescriptor   `json:"result"`
+}
+
+type ContentDescriptor struct {
+	Name     string  `json:"name"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the subset of JSON Schema that can be derived from Go types
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// WithDiscovery registers the rpc.discover method, which describes all the methods of the
+// Server as an OpenRPC document. If the Server has a version, it replaces the one in info.
+func (s *Server) WithDiscovery(info OpenRPCInfo) *Server {
+	s.methods[discoverMethod] = Method{
+		Name: discoverMethod,
+		Handler: func() (*OpenRPC, *Error) {
+			return s.openRPC(info), nil
+		},
+	}
+	return s
+}
+
+func (s *Server) openRPC(info OpenRPCInfo) *OpenRPC {
+	if s.version != "" {
+		info.Version = s.version
+	}
+
+	gen := &schemaGenerator{schemas: make(map[string]*Schema)}
+	doc := &OpenRPC{
+		OpenRPC: openRPCVersion,
+		Info:    info,
+		Methods: make([]*OpenRPCMethod, 0, len(s.methods)),
+	}
+
+	for _, method := range s.methods {
+		if method.Name == discoverMethod {
+			continue
+		}
+
+		handlerT := reflect.TypeOf(method.Handler)
+		offset := contextParams(handlerT)
+		openRPCMethod := &OpenRPCMethod{
+			Name:   method.Name,
+			Params: make([]*ContentDescriptor, len(method.Params)),
+			Result: &ContentDescriptor{Name: "result", Schema: gen.schema(handlerT.Out(0))},
+		}
+		for i, param := range method.Params {
+			openRPCMethod.Params[i] = &ContentDescriptor{
+				Name:     param.Name,
+				Required: !param.Optional,
+				Schema:   gen.schema(handlerT.In(i + offset)),
+			}
+		}
+		doc.Methods = append(doc.Methods, openRPCMethod)
+	}
+	sort.Slice(doc.Methods, func(i, j int) bool {
+		return doc.Methods[i].Name < doc.Methods[j].Name
+	})
+
+	if len(gen.schemas) > 0 {
+		doc.Components = &Components{Schemas: gen.schemas}
+	}
+	return doc
+}
+
+var customEncodingTypes = []reflect.Type{
+	reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*json.Unmarshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem(),
+}
+
+// schemaGenerator derives schemas from Go types the way encoding/json encodes them. Named
+// structs are added to the components and referenced, so that recursive types terminate.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if hasCustomEncoding(t) {
+		// the encoding is up to the type, there is nothing more to say than its name
+		return &Schema{Title: t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"} // base64 encoded
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, found := g.schemas[t.Name()]; !found {
+			g.schemas[t.Name()] = nil // reserve the name while the fields are generated
+			g.schemas[t.Name()] = g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *schemaGenerator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && !hasCustomEncoding(embedded) {
+				// the fields of embedded structs are promoted
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schema(field.Type)
+	}
+}
+
+// hasCustomEncoding reports whether t, or a pointer to it, encodes or decodes itself
+func hasCustomEncoding(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	for _, custom := range customEncodingTypes {
+		if t.Implements(custom) || ptr.Implements(custom) {
+			return true
+		}
+	}
+	return false
+}
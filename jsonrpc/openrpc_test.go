@@ -0,0 +1,100 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type discoveryInner struct {
+	Value *felt.Felt `json:"value"`
+}
+
+type discoveryEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type discoveryResult struct {
+	discoveryEmbedded
+	Name    string            `json:"name"`
+	Count   uint64            `json:"count"`
+	Inners  []*discoveryInner `json:"inners"`
+	Labels  map[string]bool   `json:"labels"`
+	Ignored int               `json:"-"`
+	Next    *discoveryResult  `json:"next,omitempty"`
+}
+
+func TestDiscovery(t *testing.T) {
+	server := jsonrpc.NewServer().WithDiscovery(jsonrpc.OpenRPCInfo{Title: "test", Version: "1.0"})
+	require.NoError(t, server.RegisterMethod(jsonrpc.Method{
+		"query",
+		[]jsonrpc.Parameter{{Name: "name"}, {Name: "limit", Optional: true}},
+		func(ctx context.Context, name string, limit *int) (*discoveryResult, *jsonrpc.Error) {
+			return nil, nil
+		},
+	}))
+	require.NoError(t, server.RegisterMethod(jsonrpc.Method{
+		"chainId",
+		nil,
+		func() (string, *jsonrpc.Error) {
+			return "", nil
+		},
+	}))
+
+	res, err := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "rpc.discover", "id": 1}`))
+	require.NoError(t, err)
+
+	var response struct {
+		Result jsonrpc.OpenRPC `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(res, &response))
+	doc := response.Result
+
+	assert.Equal(t, "1.2.6", doc.OpenRPC)
+	assert.Equal(t, jsonrpc.OpenRPCInfo{Title: "test", Version: "1.0"}, doc.Info)
+	require.Len(t, doc.Methods, 2)
+
+	t.Run("methods are sorted by name", func(t *testing.T) {
+		assert.Equal(t, "chainId", doc.Methods[0].Name)
+		assert.Empty(t, doc.Methods[0].Params)
+		assert.Equal(t, &jsonrpc.Schema{Type: "string"}, doc.Methods[0].Result.Schema)
+		assert.Equal(t, "query", doc.Methods[1].Name)
+	})
+
+	t.Run("context is not a param", func(t *testing.T) {
+		assert.Equal(t, []*jsonrpc.ContentDescriptor{
+			{Name: "name", Required: true, Schema: &jsonrpc.Schema{Type: "string"}},
+			{Name: "limit", Schema: &jsonrpc.Schema{Type: "integer"}},
+		}, doc.Methods[1].Params)
+	})
+
+	t.Run("structs are referenced from the components", func(t *testing.T) {
+		assert.Equal(t, &jsonrpc.Schema{Ref: "#/components/schemas/discoveryResult"}, doc.Methods[1].Result.Schema)
+		require.NotNil(t, doc.Components)
+		assert.Equal(t, map[string]*jsonrpc.Schema{
+			"discoveryResult": {
+				Type: "object",
+				Properties: map[string]*jsonrpc.Schema{
+					"embedded": {Type: "string"},
+					"name":     {Type: "string"},
+					"count":    {Type: "integer"},
+					"inners": {
+						Type:  "array",
+						Items: &jsonrpc.Schema{Ref: "#/components/schemas/discoveryInner"},
+					},
+					"labels": {Type: "object", AdditionalProperties: &jsonrpc.Schema{Type: "boolean"}},
+					"next":   {Ref: "#/components/schemas/discoveryResult"},
+				},
+			},
+			"discoveryInner": {
+				Type:       "object",
+				Properties: map[string]*jsonrpc.Schema{"value": {Title: "Felt"}},
+			},
+		}, doc.Components.Schemas)
+	})
+}
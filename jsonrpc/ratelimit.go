@@ -0,0 +1,76 @@
+package jsonrpc
+
+import (
+	stdsync "sync"
+	"time"
+)
+
+// idleBucketTTL is how long the bucket of a client is kept after its last request
+const idleBucketTTL = time.Minute
+
+// rateLimiter limits the number of requests per second of every client with a token bucket
+type rateLimiter struct {
+	rate  float64 // tokens added per second
+	burst float64 // size of the buckets
+
+	lock      stdsync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(requestsPerSecond uint) *rateLimiter {
+	return &rateLimiter{
+		rate:    float64(requestsPerSecond),
+		burst:   float64(requestsPerSecond),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token for every one of the requests from the bucket of client and reports
+// whether it had any left. A batch can take more tokens than the bucket holds, the client then
+// has to wait for the bucket to refill before its next request.
+func (l *rateLimiter) allow(client string, requests int) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	bucket, found := l.buckets[client]
+	if !found {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens -= float64(requests)
+	return true
+}
+
+// prune drops the buckets of clients that have been idle for a while, they are full again
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < idleBucketTTL {
+		return
+	}
+	for client, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= idleBucketTTL {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastPrune = now
+}
@@ -0,0 +1,40 @@
+package jsonrpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	assert.True(t, limiter.allow("a", 1))
+	assert.True(t, limiter.allow("a", 1))
+	assert.False(t, limiter.allow("a", 1))
+	// every client has its own bucket
+	assert.True(t, limiter.allow("b", 1))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.allow("a", 1))
+	assert.False(t, limiter.allow("a", 1))
+
+	// buckets refill up to the burst only
+	now = now.Add(time.Hour)
+	assert.True(t, limiter.allow("a", 1))
+	assert.True(t, limiter.allow("a", 1))
+	assert.False(t, limiter.allow("a", 1))
+	// idle clients are forgotten
+	assert.Len(t, limiter.buckets, 1)
+
+	// every request of a batch takes a token
+	now = now.Add(time.Hour)
+	assert.True(t, limiter.allow("a", 5))
+	now = now.Add(time.Second)
+	assert.False(t, limiter.allow("a", 1))
+	now = now.Add(time.Second)
+	assert.True(t, limiter.allow("a", 1))
+}
@@ -4,11 +4,18 @@ package jsonrpc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
+	"runtime"
 	"strings"
+	"time"
+
+	"github.com/sourcegraph/conc/panics"
+	"github.com/sourcegraph/conc/pool"
 )
 
 // Todo: add rpcErr type which implements Error() to return short string representation of the error. For example:
@@ -32,6 +39,8 @@ const (
 	MethodNotFound = -32601 // The method does not exist / is not available.
 	InvalidParams  = -32602 // Invalid method parameter(s).
 	InternalError  = -32603 // Internal JSON-RPC error.
+	RequestTimeout = -32001 // The request did not complete within its deadline.
+	LimitExceeded  = -32005 // The request exceeds a limit of the server.
 )
 
 type request struct {
@@ -92,29 +101,80 @@ type Method struct {
 }
 
 type Server struct {
-	methods map[string]Method
+	methods       map[string]Method
+	subscriptions *subscriptions
+
+	batchConcurrency int
+	maxBatchSize     int
+
+	timeout        time.Duration
+	methodTimeouts map[string]time.Duration
+
+	middlewares []Middleware
+
+	version string
 }
 
 // NewServer instantiates a JSONRPC server
 func NewServer() *Server {
 	return &Server{
-		methods: make(map[string]Method),
+		methods:          make(map[string]Method),
+		batchConcurrency: runtime.NumCPU(),
+		methodTimeouts:   make(map[string]time.Duration),
+	}
+}
+
+// WithVersion sets the version of the API that the Server serves
+func (s *Server) WithVersion(version string) *Server {
+	s.version = version
+	return s
+}
+
+// WithTimeout sets the deadline of every request, zero means no deadline. Requests that
+// exceed it are answered with a [RequestTimeout] error.
+func (s *Server) WithTimeout(timeout time.Duration) *Server {
+	s.timeout = timeout
+	return s
+}
+
+// WithMethodTimeout sets the deadline of the requests for the given method, overriding the
+// one set by [Server.WithTimeout]
+func (s *Server) WithMethodTimeout(method string, timeout time.Duration) *Server {
+	s.methodTimeouts[method] = timeout
+	return s
+}
+
+// WithBatchConcurrency sets how many requests of a batch are executed at the same time
+func (s *Server) WithBatchConcurrency(concurrency int) *Server {
+	if concurrency < 1 {
+		concurrency = 1
 	}
+	s.batchConcurrency = concurrency
+	return s
+}
+
+// WithMaxBatchSize sets the maximum number of requests in a batch. Larger batches are
+// rejected as a whole. Zero means no limit.
+func (s *Server) WithMaxBatchSize(size int) *Server {
+	s.maxBatchSize = size
+	return s
 }
 
 // RegisterMethod verifies and creates an endpoint that the server recognizes.
 //
 // - name is the method name
 // - handler is the function to be called when a request is received for the
-// associated method. It should have (any, *jsonrpc.Error) as its return type
+// associated method. It should have (any, *jsonrpc.Error) as its return type. If its
+// first parameter is a context.Context, it is cancelled once the request is abandoned
+// or its deadline is exceeded
 // - paramNames are the names of parameters in the order that they are expected
-// by the handler
+// by the handler, not including the context
 func (s *Server) RegisterMethod(method Method) error {
 	handlerT := reflect.TypeOf(method.Handler)
 	if handlerT.Kind() != reflect.Func {
 		return errors.New("handler must be a function")
 	}
-	if handlerT.NumIn() != len(method.Params) {
+	if handlerT.NumIn()-contextParams(handlerT) != len(method.Params) {
 		return errors.New("number of function params and param names must match")
 	}
 	if handlerT.NumOut() != 2 {
@@ -133,13 +193,44 @@ func (s *Server) RegisterMethod(method Method) error {
 // It returns the response in a byte array, only returns an
 // error if it can not create the response byte array
 func (s *Server) Handle(data []byte) ([]byte, error) {
-	return s.HandleReader(bytes.NewReader(data))
+	return s.HandleReader(context.Background(), bytes.NewReader(data))
 }
 
-// HandleReader processes a request to the server
+// HandleReader processes a request to the server. Handlers taking a context are cancelled
+// along with ctx.
 // It returns the response in a byte array, only returns an
 // error if it can not create the response byte array
-func (s *Server) HandleReader(reader io.Reader) ([]byte, error) {
+func (s *Server) HandleReader(ctx context.Context, reader io.Reader) ([]byte, error) {
+	return s.handleReader(ctx, reader, nil, nil, nil)
+}
+
+// HandleConn processes a request received on a persistent connection and writes the
+// response, if any, to it. Subscriptions are only available to requests handled this way.
+func (s *Server) HandleConn(ctx context.Context, conn Conn, data []byte) error {
+	return s.handleConn(ctx, conn, data, nil)
+}
+
+// admitFunc decides whether a request, or a batch of the given number of requests, is
+// handled. It returns the error the whole request is answered with if it is not.
+type admitFunc func(requests int) *Error
+
+func (s *Server) handleConn(ctx context.Context, conn Conn, data []byte, admit admitFunc) error {
+	var activate []*Subscription
+	resp, err := s.handleReader(ctx, bytes.NewReader(data), conn, &activate, admit)
+	if err == nil && resp != nil {
+		err = conn.Write(resp)
+	}
+	// the client learns about the new subscriptions from the response, notifications for
+	// them can be sent from now on
+	for _, sub := range activate {
+		close(sub.active)
+	}
+	return err
+}
+
+func (s *Server) handleReader(ctx context.Context, reader io.Reader, conn Conn, activate *[]*Subscription,
+	admit admitFunc,
+) ([]byte, error) {
 	bufferedReader := bufio.NewReader(reader)
 	requestIsBatch := isBatch(bufferedReader)
 	res := &response{
@@ -153,63 +244,109 @@ func (s *Server) HandleReader(reader io.Reader) ([]byte, error) {
 		req := new(request)
 		if jsonErr := dec.Decode(req); jsonErr != nil {
 			res.Error = &Error{Code: InvalidJson, Message: jsonErr.Error()}
-		} else if resObject, handleErr := s.handleRequest(req); handleErr != nil {
+		} else if admitErr := admitRequests(admit, 1); admitErr != nil {
+			res.Error = admitErr
+		} else if resObject, handleErr := s.handleRequest(ctx, req, conn, activate); handleErr != nil {
 			res.Error = &Error{Code: InvalidRequest, Message: handleErr.Error()}
 		} else {
 			res = resObject
 		}
 	} else {
 		var batchReq []json.RawMessage
-		var batchRes []json.RawMessage
 
 		if batchJsonErr := dec.Decode(&batchReq); batchJsonErr != nil {
 			res.Error = &Error{Code: InvalidJson, Message: batchJsonErr.Error()}
 		} else if len(batchReq) == 0 {
 			res.Error = &Error{Code: InvalidRequest, Message: "empty batch"}
+		} else if s.maxBatchSize > 0 && len(batchReq) > s.maxBatchSize {
+			res.Error = &Error{
+				Code:    InvalidRequest,
+				Message: fmt.Sprintf("batch of %d requests exceeds the limit of %d", len(batchReq), s.maxBatchSize),
+			}
+		} else if admitErr := admitRequests(admit, len(batchReq)); admitErr != nil {
+			res.Error = admitErr
 		} else {
-			for _, rawReq := range batchReq { // todo: handle async
-				var resObject *response
-
-				reqDec := json.NewDecoder(bytes.NewBuffer(rawReq))
-				reqDec.UseNumber()
-
-				req := new(request)
-				if jsonErr := reqDec.Decode(req); jsonErr != nil {
-					resObject = &response{
-						Version: "2.0",
-						Error:   &Error{Code: InvalidRequest, Message: jsonErr.Error()},
-					}
-				} else {
-					var handleErr error
-					resObject, handleErr = s.handleRequest(req)
-					if handleErr != nil {
-						resObject = &response{
-							Version: "2.0",
-							Error:   &Error{Code: InvalidRequest, Message: handleErr.Error()},
-						}
-					}
-				}
+			return s.handleBatch(ctx, batchReq, conn, activate)
+		}
+	}
 
-				if resObject != nil {
-					if resArr, jsonErr := json.Marshal(resObject); jsonErr != nil {
-						return nil, jsonErr
-					} else {
-						batchRes = append(batchRes, resArr)
-					}
-				}
-			}
+	if res == nil {
+		return nil, nil
+	}
+	return json.Marshal(res)
+}
+
+func admitRequests(admit admitFunc, requests int) *Error {
+	if admit == nil {
+		return nil
+	}
+	return admit(requests)
+}
 
-			if len(batchRes) == 0 {
-				return nil, nil
+// handleBatch executes the requests of a batch concurrently. The responses are returned in
+// the order of the requests, notifications get none.
+func (s *Server) handleBatch(ctx context.Context, batchReq []json.RawMessage, conn Conn, activate *[]*Subscription) ([]byte, error) {
+	responses := make([][]byte, len(batchReq))
+	errs := make([]error, len(batchReq))
+	// every request collects the subscriptions it creates on its own, so that they can be
+	// activated in order
+	batchActivate := make([][]*Subscription, len(batchReq))
+
+	workers := pool.New().WithMaxGoroutines(s.batchConcurrency)
+	for i := range batchReq {
+		i := i
+		workers.Go(func() {
+			var reqActivate *[]*Subscription
+			if activate != nil {
+				reqActivate = &batchActivate[i]
 			}
-			return json.Marshal(batchRes)
+			resObject := s.handleBatchRequest(ctx, batchReq[i], conn, reqActivate)
+			if resObject != nil {
+				responses[i], errs[i] = json.Marshal(resObject)
+			}
+		})
+	}
+	workers.Wait()
+
+	var batchRes []json.RawMessage
+	for i, resArr := range responses {
+		if activate != nil {
+			*activate = append(*activate, batchActivate[i]...)
+		}
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if resArr != nil {
+			batchRes = append(batchRes, resArr)
 		}
 	}
 
-	if res == nil {
+	if len(batchRes) == 0 {
 		return nil, nil
 	}
-	return json.Marshal(res)
+	return json.Marshal(batchRes)
+}
+
+func (s *Server) handleBatchRequest(ctx context.Context, rawReq json.RawMessage, conn Conn, activate *[]*Subscription) *response {
+	reqDec := json.NewDecoder(bytes.NewBuffer(rawReq))
+	reqDec.UseNumber()
+
+	req := new(request)
+	if jsonErr := reqDec.Decode(req); jsonErr != nil {
+		return &response{
+			Version: "2.0",
+			Error:   &Error{Code: InvalidRequest, Message: jsonErr.Error()},
+		}
+	}
+
+	resObject, handleErr := s.handleRequest(ctx, req, conn, activate)
+	if handleErr != nil {
+		return &response{
+			Version: "2.0",
+			Error:   &Error{Code: InvalidRequest, Message: handleErr.Error()},
+		}
+	}
+	return resObject
 }
 
 func isBatch(reader *bufio.Reader) bool {
@@ -234,46 +371,135 @@ func isNil(i any) bool {
 	return i == nil || reflect.ValueOf(i).IsNil()
 }
 
-func (s *Server) handleRequest(req *request) (*response, error) {
+func (s *Server) handleRequest(ctx context.Context, req *request, conn Conn, activate *[]*Subscription) (*response, error) {
 	if err := req.isSane(); err != nil {
 		return nil, err
 	}
 
+	// notifications are only answered if their handler could not be invoked
+	var invoked bool
+	handler := s.withMiddlewares(func(ctx context.Context, call *Call) (any, *Error) {
+		var result any
+		var err *Error
+		result, err, invoked = s.dispatch(ctx, call, conn, activate)
+		return result, err
+	})
+	result, err := handler(ctx, &Call{Method: req.Method, Params: req.Params})
+	if invoked && req.Id == nil {
+		return nil, nil
+	}
+
 	res := &response{
 		Version: "2.0",
 		Id:      req.Id,
 	}
+	if err != nil {
+		res.Error = err
+	} else {
+		res.Result = result
+	}
+	return res, nil
+}
+
+// dispatch executes call with the handler of its method. It also reports whether the
+// handler was invoked.
+func (s *Server) dispatch(ctx context.Context, call *Call, conn Conn, activate *[]*Subscription) (any, *Error, bool) {
+	if method, err, handled := s.subscriptionMethod(call, conn, activate); handled {
+		if err != nil {
+			return nil, err, false
+		}
+		// subscription handlers never block, they are not subject to deadlines
+		return s.call(context.Background(), call.Params, *method)
+	}
 
-	calledMethod, found := s.methods[req.Method]
+	calledMethod, found := s.methods[call.Method]
 	if !found {
-		res.Error = &Error{
+		return nil, &Error{
 			Code:    MethodNotFound,
 			Message: "method not found",
-		}
-		return res, nil
+		}, false
 	}
 
-	args, err := buildArguments(req.Params, calledMethod.Handler, calledMethod.Params)
+	return s.call(ctx, call.Params, calledMethod)
+}
+
+// call invokes the handler of method with params and returns its results. It also reports
+// whether the handler was invoked.
+func (s *Server) call(ctx context.Context, params any, method Method) (any, *Error, bool) {
+	args, err := buildArguments(params, method.Handler, method.Params)
 	if err != nil {
-		res.Error = &Error{
+		return nil, &Error{
 			Code:    InvalidParams,
 			Message: err.Error(),
-		}
-		return res, nil
+		}, false
 	}
 
-	tuple := reflect.ValueOf(calledMethod.Handler).Call(args)
-	if res.Id == nil { // notification
-		return nil, nil
+	timeout, found := s.methodTimeouts[method.Name]
+	if !found {
+		timeout = s.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if contextParams(reflect.TypeOf(method.Handler)) == 1 {
+		args = append([]reflect.Value{reflect.ValueOf(ctx)}, args...)
+	}
+
+	var tuple []reflect.Value
+	if timeout > 0 {
+		tuple, err = invokeWithDeadline(ctx, method.Handler, args)
+	} else {
+		// without a deadline the handler can only stop early if it takes a context, so
+		// there is nothing to gain from running it in its own goroutine
+		tuple, err = reflect.ValueOf(method.Handler).Call(args), ctx.Err()
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, &Error{Code: RequestTimeout, Message: "request timed out"}, true
+		}
+		return nil, &Error{Code: InternalError, Message: err.Error()}, true
 	}
 
 	if errAny := tuple[1].Interface(); !isNil(errAny) {
-		res.Error = errAny.(*Error)
-		return res, nil
+		return nil, errAny.(*Error), true
 	}
+	return tuple[0].Interface(), nil, true
+}
 
-	res.Result = tuple[0].Interface()
-	return res, nil
+// invokeWithDeadline calls handler with args. If ctx is done before the handler returns,
+// ctx.Err() is returned right away. The work of the handler is abandoned rather than
+// cancelled: handlers that take a context can stop early, the others run to completion
+// in the background and their result is discarded.
+func invokeWithDeadline(ctx context.Context, handler any, args []reflect.Value) ([]reflect.Value, error) {
+	var tuple []reflect.Value
+	var catcher panics.Catcher
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		catcher.Try(func() {
+			tuple = reflect.ValueOf(handler).Call(args)
+		})
+	}()
+
+	select {
+	case <-done:
+		catcher.Repanic()
+		return tuple, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// contextParams returns 1 if the first parameter of handlerT is a context.Context, 0 otherwise
+func contextParams(handlerT reflect.Type) int {
+	if handlerT.NumIn() > 0 && handlerT.In(0) == contextType {
+		return 1
+	}
+	return 0
 }
 
 func buildArguments(params, handler any, configuredParams []Parameter) ([]reflect.Value, error) {
@@ -283,6 +509,7 @@ func buildArguments(params, handler any, configuredParams []Parameter) ([]reflec
 	}
 
 	handlerType := reflect.TypeOf(handler)
+	offset := contextParams(handlerType)
 
 	handlerParamValue := func(param any, t reflect.Type) (reflect.Value, error) {
 		handlerParam := reflect.New(t)
@@ -302,12 +529,12 @@ func buildArguments(params, handler any, configuredParams []Parameter) ([]reflec
 	case reflect.Slice:
 		paramsList := params.([]any)
 
-		if len(paramsList) != handlerType.NumIn() {
+		if len(paramsList) != handlerType.NumIn()-offset {
 			return nil, errors.New("missing param in list")
 		}
 
 		for i, param := range paramsList {
-			v, err := handlerParamValue(param, handlerType.In(i))
+			v, err := handlerParamValue(param, handlerType.In(i+offset))
 			if err != nil {
 				return nil, err
 			}
@@ -321,13 +548,13 @@ func buildArguments(params, handler any, configuredParams []Parameter) ([]reflec
 			var v reflect.Value
 			if param, found := paramsMap[configuredParam.Name]; found {
 				var err error
-				v, err = handlerParamValue(param, handlerType.In(i))
+				v, err = handlerParamValue(param, handlerType.In(i+offset))
 				if err != nil {
 					return nil, err
 				}
 			} else if configuredParam.Optional {
 				// optional parameter
-				v = reflect.New(handlerType.In(i)).Elem()
+				v = reflect.New(handlerType.In(i + offset)).Elem()
 			} else {
 				return nil, errors.New("missing non-optional param")
 			}
@@ -1,7 +1,13 @@
 package jsonrpc_test
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/NethermindEth/juno/jsonrpc"
 	"github.com/stretchr/testify/assert"
@@ -44,6 +50,11 @@ func TestServer_RegisterMethod(t *testing.T) {
 			paramNames: []jsonrpc.Parameter{{Name: "param1"}, {Name: "param2"}},
 			want:       "second return value must be a *jsonrpc.Error",
 		},
+		"context named as param": {
+			handler:    func(ctx context.Context, param1 int) (int, *jsonrpc.Error) { return 0, nil },
+			paramNames: []jsonrpc.Parameter{{Name: "ctx"}, {Name: "param1"}},
+			want:       "number of function params and param names must match",
+		},
 	}
 
 	for desc, test := range tests {
@@ -331,3 +342,193 @@ func TestHandle(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleBatchConcurrency(t *testing.T) {
+	batch := func(n int, notifyEvery int) string {
+		reqs := make([]string, n)
+		for i := range reqs {
+			if notifyEvery > 0 && i%notifyEvery == 0 {
+				reqs[i] = fmt.Sprintf(`{"jsonrpc": "2.0", "method": "echo", "params": [%d]}`, i)
+			} else {
+				reqs[i] = fmt.Sprintf(`{"jsonrpc": "2.0", "method": "echo", "params": [%d], "id": %d}`, i, i)
+			}
+		}
+		return "[" + strings.Join(reqs, ",") + "]"
+	}
+
+	t.Run("requests are executed in parallel", func(t *testing.T) {
+		const size = 4
+		var started sync.WaitGroup
+		started.Add(size)
+		allStarted := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(allStarted)
+		}()
+
+		server := jsonrpc.NewServer().WithBatchConcurrency(size)
+		require.NoError(t, server.RegisterMethod(jsonrpc.Method{
+			Name:   "echo",
+			Params: []jsonrpc.Parameter{{Name: "n"}},
+			Handler: func(n int) (int, *jsonrpc.Error) {
+				started.Done()
+				select {
+				case <-allStarted:
+					return n, nil
+				case <-time.After(5 * time.Second):
+					return 0, &jsonrpc.Error{Code: 1, Message: "requests were not executed in parallel"}
+				}
+			},
+		}))
+
+		res, err := server.Handle([]byte(batch(size, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, `[{"jsonrpc":"2.0","result":0,"id":0},{"jsonrpc":"2.0","result":1,"id":1},`+
+			`{"jsonrpc":"2.0","result":2,"id":2},{"jsonrpc":"2.0","result":3,"id":3}]`, string(res))
+	})
+
+	t.Run("concurrency is limited and the order is preserved", func(t *testing.T) {
+		const limit = 3
+		var inFlight, maxInFlight int32
+		server := jsonrpc.NewServer().WithBatchConcurrency(limit)
+		require.NoError(t, server.RegisterMethod(jsonrpc.Method{
+			Name:   "echo",
+			Params: []jsonrpc.Parameter{{Name: "n"}},
+			Handler: func(n int) (int, *jsonrpc.Error) {
+				current := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+				for {
+					highest := atomic.LoadInt32(&maxInFlight)
+					if current <= highest || atomic.CompareAndSwapInt32(&maxInFlight, highest, current) {
+						break
+					}
+				}
+				// later requests finish first
+				time.Sleep(time.Duration(50-n) * time.Millisecond)
+				return n, nil
+			},
+		}))
+
+		res, err := server.Handle([]byte(batch(50, 7)))
+		require.NoError(t, err)
+
+		var expected []string
+		for i := 0; i < 50; i++ {
+			if i%7 != 0 {
+				expected = append(expected, fmt.Sprintf(`{"jsonrpc":"2.0","result":%d,"id":%d}`, i, i))
+			}
+		}
+		assert.Equal(t, "["+strings.Join(expected, ",")+"]", string(res))
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(limit))
+	})
+
+	t.Run("batches beyond the maximum size are rejected", func(t *testing.T) {
+		var calls int32
+		server := jsonrpc.NewServer().WithMaxBatchSize(2)
+		require.NoError(t, server.RegisterMethod(jsonrpc.Method{
+			Name:   "echo",
+			Params: []jsonrpc.Parameter{{Name: "n"}},
+			Handler: func(n int) (int, *jsonrpc.Error) {
+				atomic.AddInt32(&calls, 1)
+				return n, nil
+			},
+		}))
+
+		res, err := server.Handle([]byte(batch(2, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, `[{"jsonrpc":"2.0","result":0,"id":0},{"jsonrpc":"2.0","result":1,"id":1}]`, string(res))
+
+		res, err = server.Handle([]byte(batch(3, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"batch of 3 requests exceeds the limit of 2"},"id":null}`,
+			string(res))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestHandleContext(t *testing.T) {
+	cancelled := make(chan struct{}, 1)
+	server := jsonrpc.NewServer().WithTimeout(time.Minute).WithMethodTimeout("slow", 50*time.Millisecond)
+	methods := []jsonrpc.Method{
+		{
+			"double",
+			[]jsonrpc.Parameter{{Name: "num"}},
+			func(ctx context.Context, num int) (int, *jsonrpc.Error) {
+				if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+					return 0, &jsonrpc.Error{Code: 1, Message: "no deadline"}
+				}
+				return num * 2, nil
+			},
+		},
+		{
+			"slow",
+			[]jsonrpc.Parameter{},
+			func(ctx context.Context) (int, *jsonrpc.Error) {
+				<-ctx.Done()
+				cancelled <- struct{}{}
+				return 0, nil
+			},
+		},
+	}
+	for _, m := range methods {
+		require.NoError(t, server.RegisterMethod(m))
+	}
+
+	tests := map[string]struct {
+		req string
+		res string
+	}{
+		"list params": {
+			req: `{"jsonrpc": "2.0", "method": "double", "params": [21], "id": 1}`,
+			res: `{"jsonrpc":"2.0","result":42,"id":1}`,
+		},
+		"named params": {
+			req: `{"jsonrpc": "2.0", "method": "double", "params": {"num": 21}, "id": 2}`,
+			res: `{"jsonrpc":"2.0","result":42,"id":2}`,
+		},
+		"exceeded deadline": {
+			req: `{"jsonrpc": "2.0", "method": "slow", "params": [], "id": 3}`,
+			res: `{"jsonrpc":"2.0","error":{"code":-32001,"message":"request timed out"},"id":3}`,
+		},
+	}
+	for desc, test := range tests {
+		t.Run(desc, func(t *testing.T) {
+			res, err := server.Handle([]byte(test.req))
+			require.NoError(t, err)
+			assert.Equal(t, test.res, string(res))
+		})
+	}
+	<-cancelled
+
+	t.Run("handlers are cancelled along with the request", func(t *testing.T) {
+		server.WithMethodTimeout("slow", 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+		res, err := server.HandleReader(ctx,
+			strings.NewReader(`{"jsonrpc": "2.0", "method": "slow", "params": [], "id": 4}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"context canceled"},"id":4}`, string(res))
+		<-cancelled
+	})
+
+	t.Run("handlers without a context time out", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		require.NoError(t, server.RegisterMethod(jsonrpc.Method{
+			"blocking",
+			[]jsonrpc.Parameter{},
+			func() (int, *jsonrpc.Error) {
+				<-release
+				return 0, nil
+			},
+		}))
+		server.WithMethodTimeout("blocking", 50*time.Millisecond)
+
+		res, err := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "blocking", "id": 5}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32001,"message":"request timed out"},"id":5}`, string(res))
+	})
+}
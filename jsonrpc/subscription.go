@@ -0,0 +1,241 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	stdsync "sync"
+)
+
+// Conn is a persistent connection that requests are received on. Besides the responses,
+// the server can send notifications over it at any time.
+type Conn interface {
+	// Write sends a complete JSON-RPC message to the client
+	Write(msg []byte) error
+	// Done returns a channel that is closed once the connection is closed
+	Done() <-chan struct{}
+}
+
+// SubscriptionMethods names the methods that clients call to manage their subscriptions
+// and the method of the notifications that are sent for them.
+type SubscriptionMethods struct {
+	Subscribe    string
+	Unsubscribe  string
+	Notification string
+}
+
+// Topic is a stream of notifications that clients can subscribe to.
+//
+// Handler is called every time a client subscribes to the topic. It should have the
+// signature func(*Subscription) *Error, or func(*Subscription, T) *Error if the topic
+// takes parameters, which are then passed as the second parameter of the subscribe
+// method. The handler must not block: it should send the notifications from a separate
+// goroutine until the Done channel of the subscription is closed.
+type Topic struct {
+	Name    string
+	Handler any
+}
+
+// Subscription is the subscription of a client to a [Topic]
+type Subscription struct {
+	ID uint64
+
+	conn         Conn
+	notification string
+	// active is closed once the response to the subscribe request is sent, notifications
+	// are held back until then
+	active    chan struct{}
+	done      chan struct{}
+	closeOnce stdsync.Once
+}
+
+type notification struct {
+	Version string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type subscriptionResult struct {
+	Subscription uint64 `json:"subscription"`
+	Result       any    `json:"result"`
+}
+
+// Notify sends result to the subscriber. It blocks until the subscribe request has been
+// responded to.
+func (s *Subscription) Notify(result any) error {
+	select {
+	case <-s.active:
+	case <-s.done:
+		return errors.New("subscription is closed")
+	}
+
+	msg, err := json.Marshal(notification{
+		Version: "2.0",
+		Method:  s.notification,
+		Params:  subscriptionResult{Subscription: s.ID, Result: result},
+	})
+	if err != nil {
+		return err
+	}
+	return s.conn.Write(msg)
+}
+
+// Done returns a channel that is closed once the client unsubscribes or disconnects
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// subscriptions keeps track of the subscriptions of all the connections of a Server
+type subscriptions struct {
+	methods SubscriptionMethods
+	topics  map[string]Topic
+
+	lock   stdsync.Mutex
+	nextID uint64
+	subs   map[uint64]*Subscription
+}
+
+// RegisterSubscriptions enables subscriptions to the given topics through the given methods.
+// Subscriptions are only available to requests handled with [Server.HandleConn].
+func (s *Server) RegisterSubscriptions(methods SubscriptionMethods, topics []Topic) error {
+	if methods.Subscribe == "" || methods.Unsubscribe == "" || methods.Notification == "" {
+		return errors.New("subscription method names must not be empty")
+	}
+
+	subs := &subscriptions{
+		methods: methods,
+		topics:  make(map[string]Topic),
+		subs:    make(map[uint64]*Subscription),
+	}
+	for _, topic := range topics {
+		if err := checkTopicHandler(topic.Handler); err != nil {
+			return err
+		}
+		subs.topics[topic.Name] = topic
+	}
+	s.subscriptions = subs
+	return nil
+}
+
+func checkTopicHandler(handler any) error {
+	handlerT := reflect.TypeOf(handler)
+	if handlerT == nil || handlerT.Kind() != reflect.Func {
+		return errors.New("topic handler must be a function")
+	}
+	if handlerT.NumIn() < 1 || handlerT.NumIn() > 2 || handlerT.In(0) != reflect.TypeOf(&Subscription{}) {
+		return errors.New("topic handler must take a *jsonrpc.Subscription and at most one other parameter")
+	}
+	if handlerT.NumOut() != 1 || handlerT.Out(0) != reflect.TypeOf(&Error{}) {
+		return errors.New("topic handler must return a *jsonrpc.Error")
+	}
+	return nil
+}
+
+// subscriptionMethod returns the method that handles call if it is a subscribe or
+// unsubscribe request. It returns false if call is not a subscription request.
+func (s *Server) subscriptionMethod(call *Call, conn Conn, activate *[]*Subscription) (*Method, *Error, bool) {
+	if s.subscriptions == nil {
+		return nil, nil, false
+	}
+
+	method := &Method{Name: call.Method}
+	switch call.Method {
+	case s.subscriptions.methods.Subscribe:
+		method.Params = []Parameter{{Name: "topic"}, {Name: "params", Optional: true}}
+		method.Handler = func(topic string, params json.RawMessage) (uint64, *Error) {
+			return s.subscriptions.subscribe(conn, topic, params, activate)
+		}
+		// topics without parameters are subscribed to with the topic name alone
+		if list, ok := call.Params.([]any); ok && len(list) == 1 {
+			call.Params = append(list, nil)
+		}
+	case s.subscriptions.methods.Unsubscribe:
+		method.Params = []Parameter{{Name: "id"}}
+		method.Handler = func(id uint64) (bool, *Error) {
+			return s.subscriptions.unsubscribe(conn, id), nil
+		}
+	default:
+		return nil, nil, false
+	}
+
+	if conn == nil {
+		return nil, &Error{Code: MethodNotFound, Message: "subscriptions are not supported on this transport"}, true
+	}
+	return method, nil, true
+}
+
+func (subs *subscriptions) subscribe(conn Conn, topicName string, params json.RawMessage,
+	activate *[]*Subscription,
+) (uint64, *Error) {
+	topic, found := subs.topics[topicName]
+	if !found {
+		return 0, &Error{Code: InvalidParams, Message: "unknown topic"}
+	}
+
+	subs.lock.Lock()
+	subs.nextID++
+	sub := &Subscription{
+		ID:           subs.nextID,
+		conn:         conn,
+		notification: subs.methods.Notification,
+		active:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	subs.subs[sub.ID] = sub
+	subs.lock.Unlock()
+
+	args := []reflect.Value{reflect.ValueOf(sub)}
+	handlerT := reflect.TypeOf(topic.Handler)
+	if handlerT.NumIn() == 2 {
+		arg := reflect.New(handlerT.In(1))
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, arg.Interface()); err != nil {
+				subs.remove(sub.ID)
+				return 0, &Error{Code: InvalidParams, Message: err.Error()}
+			}
+		}
+		args = append(args, arg.Elem())
+	}
+
+	if errAny := reflect.ValueOf(topic.Handler).Call(args)[0].Interface(); !isNil(errAny) {
+		subs.remove(sub.ID)
+		return 0, errAny.(*Error)
+	}
+
+	go func() {
+		select {
+		case <-conn.Done():
+			subs.remove(sub.ID)
+		case <-sub.done:
+		}
+	}()
+	*activate = append(*activate, sub)
+	return sub.ID, nil
+}
+
+// unsubscribe closes the subscription with the given id if it belongs to conn
+func (subs *subscriptions) unsubscribe(conn Conn, id uint64) bool {
+	subs.lock.Lock()
+	sub, found := subs.subs[id]
+	subs.lock.Unlock()
+	if !found || sub.conn != conn {
+		return false
+	}
+	subs.remove(id)
+	return true
+}
+
+func (subs *subscriptions) remove(id uint64) {
+	subs.lock.Lock()
+	sub, found := subs.subs[id]
+	delete(subs.subs, id)
+	subs.lock.Unlock()
+
+	if found {
+		sub.close()
+	}
+}
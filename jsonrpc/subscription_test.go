@@ -0,0 +1,110 @@
+package jsonrpc_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsocketSubscriptions(t *testing.T) {
+	ws := newTestWebsocket().WithSubscriptions(jsonrpc.SubscriptionMethods{
+		Subscribe:    "subscribe",
+		Unsubscribe:  "unsubscribe",
+		Notification: "subscription",
+	}, []jsonrpc.Topic{{
+		Name: "countdown",
+		Handler: func(sub *jsonrpc.Subscription, from int) *jsonrpc.Error {
+			if from < 0 {
+				return &jsonrpc.Error{Code: 1, Message: "negative"}
+			}
+			go func() {
+				for i := from; i >= 0; i-- {
+					if err := sub.Notify(i); err != nil {
+						return
+					}
+				}
+			}()
+			return nil
+		},
+	}})
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	conn := dialWebsocket(t, url)
+	write := func(t *testing.T, req string) {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	}
+	read := func(t *testing.T) string {
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		return string(msg)
+	}
+
+	t.Run("notifications follow the subscribe response", func(t *testing.T) {
+		write(t, `{"jsonrpc": "2.0", "method": "subscribe", "params": ["countdown", 2], "id": 1}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":1,"id":1}`, read(t))
+		for _, i := range []string{"2", "1", "0"} {
+			assert.Equal(t, `{"jsonrpc":"2.0","method":"subscription","params":{"subscription":1,"result":`+i+`}}`,
+				read(t))
+		}
+	})
+
+	t.Run("topic errors are returned", func(t *testing.T) {
+		write(t, `{"jsonrpc": "2.0", "method": "subscribe", "params": ["countdown", -1], "id": 2}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":1,"message":"negative"},"id":2}`, read(t))
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		write(t, `{"jsonrpc": "2.0", "method": "subscribe", "params": ["countup", 1], "id": 3}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32602,"message":"unknown topic"},"id":3}`, read(t))
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		write(t, `{"jsonrpc": "2.0", "method": "unsubscribe", "params": [1], "id": 4}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":true,"id":4}`, read(t))
+		write(t, `{"jsonrpc": "2.0", "method": "unsubscribe", "params": [1], "id": 5}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":false,"id":5}`, read(t))
+	})
+
+	t.Run("subscriptions of other connections cannot be closed", func(t *testing.T) {
+		write(t, `{"jsonrpc": "2.0", "method": "subscribe", "params": ["countdown", 0], "id": 6}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":3,"id":6}`, read(t))
+		read(t) // notification
+
+		other := dialWebsocket(t, url)
+		require.NoError(t, other.WriteMessage(websocket.TextMessage,
+			[]byte(`{"jsonrpc": "2.0", "method": "unsubscribe", "params": [3], "id": 7}`)))
+		_, msg, err := other.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":false,"id":7}`, string(msg))
+	})
+}
+
+func TestSubscriptionsRequireConnection(t *testing.T) {
+	server := jsonrpc.NewServer()
+	require.NoError(t, server.RegisterSubscriptions(jsonrpc.SubscriptionMethods{
+		Subscribe:    "subscribe",
+		Unsubscribe:  "unsubscribe",
+		Notification: "subscription",
+	}, []jsonrpc.Topic{{Name: "topic", Handler: func(*jsonrpc.Subscription) *jsonrpc.Error { return nil }}}))
+
+	resp, err := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "subscribe", "params": ["topic"], "id": 1}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"subscriptions are not supported on this transport"},"id":1}`,
+		string(resp))
+
+	t.Run("invalid topic handlers are rejected", func(t *testing.T) {
+		err := server.RegisterSubscriptions(jsonrpc.SubscriptionMethods{
+			Subscribe:    "subscribe",
+			Unsubscribe:  "unsubscribe",
+			Notification: "subscription",
+		}, []jsonrpc.Topic{{Name: "topic", Handler: func() *jsonrpc.Error { return nil }}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,62 @@
+package jsonrpc
+
+import "strings"
+
+// versions routes the requests of a transport to a Server by URL path, so that several
+// versions of an API can be served side by side. The root Server handles the requests of
+// all the paths that no version is registered for.
+//
+// Options applied through the transport are applied to every Server, including the ones of
+// versions that are added afterwards.
+type versions struct {
+	root    *Server
+	servers map[string]*Server
+	options []func(*Server)
+}
+
+func newVersions(methods []Method) *versions {
+	return &versions{
+		root:    newServerWithMethods(methods),
+		servers: make(map[string]*Server),
+	}
+}
+
+func newServerWithMethods(methods []Method) *Server {
+	server := NewServer()
+	for _, method := range methods {
+		if err := server.RegisterMethod(method); err != nil {
+			panic(err)
+		}
+	}
+	return server
+}
+
+// configure applies option to all the current and future Servers
+func (v *versions) configure(option func(*Server)) {
+	v.options = append(v.options, option)
+	option(v.root)
+	for _, server := range v.servers {
+		option(server)
+	}
+}
+
+// add serves version of the API on path with the given methods
+func (v *versions) add(path, version string, methods []Method) {
+	server := newServerWithMethods(methods).WithVersion(version)
+	for _, option := range v.options {
+		option(server)
+	}
+	v.servers[normalisePath(path)] = server
+}
+
+// server returns the Server that handles the requests sent to path
+func (v *versions) server(path string) *Server {
+	if server, found := v.servers[normalisePath(path)]; found {
+		return server
+	}
+	return v.root
+}
+
+func normalisePath(path string) string {
+	return "/" + strings.Trim(path, "/")
+}
@@ -0,0 +1,366 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	stdsync "sync"
+	"time"
+
+	"github.com/NethermindEth/juno/utils"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultMaxWsConnections = 128
+	defaultMaxWsMessageSize = 10 * 1024 * 1024 // 10 MiB
+	wsWriteTimeout          = 10 * time.Second
+)
+
+// Websocket serves the registered Methods over persistent WebSocket connections. Every text
+// message received on a connection is handled as a request or batch of requests and the
+// response, if any, is sent back on the same connection.
+type Websocket struct {
+	listener listener
+
+	rpc      *versions
+	http     *http.Server
+	upgrader websocket.Upgrader
+	cors     *cors
+	log      utils.Logger
+
+	maxConnections int
+	maxMessageSize int64
+	rateLimiter    *rateLimiter
+	inFlight       chan struct{}
+
+	connsLock   stdsync.Mutex
+	activeConns int
+	conns       map[*websocket.Conn]struct{}
+	connsWg     stdsync.WaitGroup
+	closed      bool
+}
+
+func NewWebsocket(port uint16, methods []Method, log utils.Logger) *Websocket {
+	ws := &Websocket{
+		rpc:            newVersions(methods),
+		listener:       newListener(port),
+		http:           &http.Server{},
+		log:            log,
+		maxConnections: defaultMaxWsConnections,
+		maxMessageSize: defaultMaxWsMessageSize,
+		conns:          make(map[*websocket.Conn]struct{}),
+	}
+	ws.http.Handler = ws
+	ws.upgrader.CheckOrigin = ws.checkOrigin
+	return ws
+}
+
+// WithVersion serves version of the API on path with the given methods. Connections to
+// any other path are served the methods the Websocket was created with.
+func (ws *Websocket) WithVersion(path, version string, methods []Method) *Websocket {
+	ws.rpc.add(path, version, methods)
+	return ws
+}
+
+// WithSubscriptions lets clients subscribe to the given topics through the given methods
+func (ws *Websocket) WithSubscriptions(methods SubscriptionMethods, topics []Topic) *Websocket {
+	ws.rpc.configure(func(s *Server) {
+		if err := s.RegisterSubscriptions(methods, topics); err != nil {
+			panic(err)
+		}
+	})
+	return ws
+}
+
+// WithBatchConcurrency sets how many requests of a batch are executed at the same time
+func (ws *Websocket) WithBatchConcurrency(concurrency int) *Websocket {
+	ws.rpc.configure(func(s *Server) { s.WithBatchConcurrency(concurrency) })
+	return ws
+}
+
+// WithMaxBatchSize sets the maximum number of requests in a batch, zero means no limit
+func (ws *Websocket) WithMaxBatchSize(size int) *Websocket {
+	ws.rpc.configure(func(s *Server) { s.WithMaxBatchSize(size) })
+	return ws
+}
+
+// WithTimeout sets the deadline of every request, zero means no deadline
+func (ws *Websocket) WithTimeout(timeout time.Duration) *Websocket {
+	ws.rpc.configure(func(s *Server) { s.WithTimeout(timeout) })
+	return ws
+}
+
+// WithMethodTimeout sets the deadline of the requests for the given method
+func (ws *Websocket) WithMethodTimeout(method string, timeout time.Duration) *Websocket {
+	ws.rpc.configure(func(s *Server) { s.WithMethodTimeout(method, timeout) })
+	return ws
+}
+
+// WithDiscovery registers the rpc.discover method, see [Server.WithDiscovery]
+func (ws *Websocket) WithDiscovery(info OpenRPCInfo) *Websocket {
+	ws.rpc.configure(func(s *Server) { s.WithDiscovery(info) })
+	return ws
+}
+
+// WithMiddleware appends middlewares to the chain that every request goes through
+func (ws *Websocket) WithMiddleware(middlewares ...Middleware) *Websocket {
+	ws.rpc.configure(func(s *Server) { s.WithMiddleware(middlewares...) })
+	return ws
+}
+
+// WithHost sets the host or IP address to listen on, an empty host listens on all interfaces
+func (ws *Websocket) WithHost(host string) *Websocket {
+	ws.listener.host = host
+	return ws
+}
+
+// WithTLS serves secure WebSocket connections with the certificate and private key in the
+// given PEM files
+func (ws *Websocket) WithTLS(certFile, keyFile string) *Websocket {
+	ws.listener.certFile, ws.listener.keyFile = certFile, keyFile
+	return ws
+}
+
+// WithCORS lets browsers open connections from pages of the given origins, "*" allows any
+// origin. Without it only pages served from the same host as the Websocket can connect.
+func (ws *Websocket) WithCORS(origins []string) *Websocket {
+	ws.cors = nil
+	if len(origins) > 0 {
+		ws.cors = newCors(origins)
+	}
+	return ws
+}
+
+// WithMaxConnections sets the number of connections that can be open at the same time.
+// Connection attempts beyond the limit are rejected with [http.StatusServiceUnavailable].
+func (ws *Websocket) WithMaxConnections(maxConnections int) *Websocket {
+	ws.maxConnections = maxConnections
+	return ws
+}
+
+// WithMaxMessageSize sets the maximum size in bytes of a message read from a connection.
+// Connections that send a larger message are closed. Zero means no limit.
+func (ws *Websocket) WithMaxMessageSize(size int64) *Websocket {
+	ws.maxMessageSize = size
+	return ws
+}
+
+// WithRateLimit sets how many requests per second every client IP can make over all its
+// connections, every request of a batch counts. Messages beyond the limit are answered with a
+// [LimitExceeded] error. Zero means no limit.
+func (ws *Websocket) WithRateLimit(requestsPerSecond uint) *Websocket {
+	ws.rateLimiter = nil
+	if requestsPerSecond > 0 {
+		ws.rateLimiter = newRateLimiter(requestsPerSecond)
+	}
+	return ws
+}
+
+// WithMaxInFlight sets how many messages can be handled at the same time over all the
+// connections. Messages beyond the limit are answered with a [LimitExceeded] error. Zero
+// means no limit.
+func (ws *Websocket) WithMaxInFlight(maxInFlight uint) *Websocket {
+	ws.inFlight = nil
+	if maxInFlight > 0 {
+		ws.inFlight = make(chan struct{}, maxInFlight)
+	}
+	return ws
+}
+
+// Run starts to listen for WebSocket connections. Once ctx is cancelled no new connections
+// are accepted and the open ones are closed.
+func (ws *Websocket) Run(ctx context.Context) error {
+	listener, listenErr := ws.listener.listen()
+	if listenErr != nil {
+		return listenErr
+	}
+
+	go func() {
+		var err error
+		for ; !errors.Is(err, http.ErrServerClosed); err = ws.http.Serve(listener) {
+			time.Sleep(time.Second) // retry if server was not closed
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		if err := ws.http.Shutdown(context.Background()); err != nil {
+			ws.log.Warnw("Error shutting down the websocket server", "err", err)
+		}
+		ws.closeConnections()
+	}()
+
+	return nil
+}
+
+// ServeHTTP upgrades an incoming HTTP request to a WebSocket connection and serves it
+// until it is closed
+func (ws *Websocket) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	if !ws.acquireConnection() {
+		http.Error(writer, "too many connections", http.StatusServiceUnavailable)
+		return
+	}
+	defer ws.connsWg.Done()
+
+	conn, err := ws.upgrader.Upgrade(writer, req, nil)
+	if err != nil {
+		// Upgrade already replied to the client
+		ws.releaseConnection(nil)
+		return
+	}
+	defer ws.releaseConnection(conn)
+
+	if !ws.trackConnection(conn) {
+		conn.Close()
+		return
+	}
+	conn.SetReadLimit(ws.maxMessageSize)
+	ws.serveConnection(req.Context(), ws.rpc.server(req.URL.Path), conn, clientIP(req))
+}
+
+// checkOrigin allows the connections of clients that are not browsers, which send no Origin
+// header, of pages of the allowed origins and of pages served from the same host
+func (ws *Websocket) checkOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if ws.cors != nil && ws.cors.allowed(origin) {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(originURL.Host, req.Host)
+}
+
+// wsConn is a WebSocket connection that responses and notifications can be written to
+// concurrently
+type wsConn struct {
+	conn      *websocket.Conn
+	writeLock stdsync.Mutex
+	done      chan struct{}
+}
+
+func (c *wsConn) Write(msg []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+func (c *wsConn) Done() <-chan struct{} {
+	return c.done
+}
+
+func (ws *Websocket) serveConnection(ctx context.Context, rpc *Server, conn *websocket.Conn, client string) {
+	wsc := &wsConn{conn: conn, done: make(chan struct{})}
+	var admit admitFunc
+	if ws.rateLimiter != nil {
+		admit = func(requests int) *Error {
+			if ws.rateLimiter.allow(client, requests) {
+				return nil
+			}
+			return errRateLimited
+		}
+	}
+
+	defer func() {
+		close(wsc.done)
+		conn.Close()
+	}()
+	for {
+		msgType, msg, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) &&
+				!errors.Is(err, net.ErrClosed) {
+				ws.log.Debugw("Websocket connection closed", "err", err)
+			}
+			return
+		}
+		if msgType != websocket.TextMessage {
+			continue
+		}
+
+		if err = ws.handleMessage(ctx, rpc, wsc, msg, admit); err != nil {
+			ws.log.Debugw("Failed handling websocket request", "err", err)
+			return
+		}
+	}
+}
+
+func (ws *Websocket) handleMessage(ctx context.Context, rpc *Server, wsc *wsConn, msg []byte, admit admitFunc) error {
+	if ws.inFlight != nil {
+		select {
+		case ws.inFlight <- struct{}{}:
+			defer func() { <-ws.inFlight }()
+		default:
+			resp, err := errorResponse(errTooManyInFlight)
+			if err != nil {
+				return err
+			}
+			return wsc.Write(resp)
+		}
+	}
+	return rpc.handleConn(ctx, wsc, msg, admit)
+}
+
+// acquireConnection reserves a slot for a new connection. It returns false if the
+// connection limit is reached or the server is shutting down.
+func (ws *Websocket) acquireConnection() bool {
+	ws.connsLock.Lock()
+	defer ws.connsLock.Unlock()
+
+	if ws.closed || ws.activeConns >= ws.maxConnections {
+		return false
+	}
+	ws.activeConns++
+	ws.connsWg.Add(1)
+	return true
+}
+
+// trackConnection registers conn so that it is closed on shutdown. It returns false if
+// the server started shutting down in the meantime.
+func (ws *Websocket) trackConnection(conn *websocket.Conn) bool {
+	ws.connsLock.Lock()
+	defer ws.connsLock.Unlock()
+
+	if ws.closed {
+		return false
+	}
+	ws.conns[conn] = struct{}{}
+	return true
+}
+
+// releaseConnection frees the slot reserved by acquireConnection
+func (ws *Websocket) releaseConnection(conn *websocket.Conn) {
+	ws.connsLock.Lock()
+	defer ws.connsLock.Unlock()
+
+	ws.activeConns--
+	if conn != nil {
+		delete(ws.conns, conn)
+	}
+}
+
+// closeConnections sends a close message on all open connections, closes them and waits
+// for their handlers to return
+func (ws *Websocket) closeConnections() {
+	ws.connsLock.Lock()
+	ws.closed = true
+	for conn := range ws.conns {
+		deadline := time.Now().Add(time.Second)
+		closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+		if err := conn.WriteControl(websocket.CloseMessage, closeMsg, deadline); err != nil {
+			ws.log.Debugw("Failed sending websocket close message", "err", err)
+		}
+		conn.Close()
+	}
+	ws.connsLock.Unlock()
+
+	ws.connsWg.Wait()
+}
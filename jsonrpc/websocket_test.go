@@ -0,0 +1,246 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebsocket() *jsonrpc.Websocket {
+	return newTestWebsocketOnPort(0)
+}
+
+func newTestWebsocketOnPort(port uint16) *jsonrpc.Websocket {
+	return jsonrpc.NewWebsocket(port, []jsonrpc.Method{{
+		"subtract",
+		[]jsonrpc.Parameter{{Name: "minuend"}, {Name: "subtrahend"}},
+		func(a, b int) (int, *jsonrpc.Error) {
+			return a - b, nil
+		},
+	}}, utils.NewNopZapLogger())
+}
+
+func dialWebsocket(t *testing.T, url string) *websocket.Conn {
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestWebsocket(t *testing.T) {
+	srv := httptest.NewServer(newTestWebsocket())
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	conn := dialWebsocket(t, url)
+	roundTrip := func(t *testing.T, req string) string {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+		_, resp, err := conn.ReadMessage()
+		require.NoError(t, err)
+		return string(resp)
+	}
+
+	t.Run("single request", func(t *testing.T) {
+		resp := roundTrip(t, `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":19,"id":1}`, resp)
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		resp := roundTrip(t, `[
+			{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1},
+			{"jsonrpc": "2.0", "method": "subtract", "params": [23, 42], "id": 2}
+		]`)
+		assert.Equal(t, `[{"jsonrpc":"2.0","result":19,"id":1},{"jsonrpc":"2.0","result":-19,"id":2}]`, resp)
+	})
+
+	t.Run("notifications get no response", func(t *testing.T) {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage,
+			[]byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [1, 1]}`)))
+		resp := roundTrip(t, `{"jsonrpc": "2.0", "method": "subtract", "params": [3, 1], "id": 3}`)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":2,"id":3}`, resp)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		resp := roundTrip(t, `{]`)
+		assert.Contains(t, resp, `"code":-32700`)
+	})
+}
+
+func TestWebsocketCORS(t *testing.T) {
+	dial := func(t *testing.T, srv *httptest.Server, origin string) error {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), header)
+		if resp != nil {
+			require.NoError(t, resp.Body.Close())
+		}
+		if err == nil {
+			conn.Close()
+		}
+		return err
+	}
+
+	t.Run("allowed origins", func(t *testing.T) {
+		srv := httptest.NewServer(newTestWebsocket().WithCORS([]string{"https://a.example"}))
+		defer srv.Close()
+
+		assert.NoError(t, dial(t, srv, "https://a.example"))
+		assert.Error(t, dial(t, srv, "https://c.example"))
+		assert.NoError(t, dial(t, srv, ""))
+	})
+
+	t.Run("any origin", func(t *testing.T) {
+		srv := httptest.NewServer(newTestWebsocket().WithCORS([]string{"*"}))
+		defer srv.Close()
+
+		assert.NoError(t, dial(t, srv, "https://c.example"))
+	})
+
+	t.Run("same origin only by default", func(t *testing.T) {
+		srv := httptest.NewServer(newTestWebsocket())
+		defer srv.Close()
+
+		assert.Error(t, dial(t, srv, "https://a.example"))
+		assert.NoError(t, dial(t, srv, srv.URL))
+		assert.NoError(t, dial(t, srv, ""))
+	})
+}
+
+func TestWebsocketLimits(t *testing.T) {
+	srv := httptest.NewServer(newTestWebsocket().WithMaxConnections(1).WithMaxMessageSize(64))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	conn := dialWebsocket(t, url)
+
+	t.Run("connections beyond the limit are rejected", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		require.Error(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+
+	t.Run("messages beyond the limit close the connection", func(t *testing.T) {
+		req := `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": "` +
+			strings.Repeat("a", 64) + `"}`
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), err)
+	})
+
+	t.Run("closed connections free up their slot", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				return false
+			}
+			resp.Body.Close()
+			conn.Close()
+			return true
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestWebsocketRequestLimits(t *testing.T) {
+	release := make(chan struct{})
+	newServer := func() *jsonrpc.Websocket {
+		return jsonrpc.NewWebsocket(0, []jsonrpc.Method{
+			{
+				"subtract",
+				[]jsonrpc.Parameter{{Name: "minuend"}, {Name: "subtrahend"}},
+				func(a, b int) (int, *jsonrpc.Error) {
+					return a - b, nil
+				},
+			},
+			{
+				"block",
+				[]jsonrpc.Parameter{},
+				func() (int, *jsonrpc.Error) {
+					<-release
+					return 0, nil
+				},
+			},
+		}, utils.NewNopZapLogger())
+	}
+	roundTrip := func(t *testing.T, conn *websocket.Conn, req string) string {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+		_, resp, err := conn.ReadMessage()
+		require.NoError(t, err)
+		return string(resp)
+	}
+	const subtract = `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`
+	const limited = `{"jsonrpc":"2.0","error":{"code":-32005,"message":"rate limit exceeded"},"id":null}`
+
+	t.Run("requests per second", func(t *testing.T) {
+		srv := httptest.NewServer(newServer().WithRateLimit(2))
+		defer srv.Close()
+		url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+		conn := dialWebsocket(t, url)
+		assert.Contains(t, roundTrip(t, conn, "["+subtract+","+subtract+"]"), `"result":19`)
+		// the limit is shared by all the connections of a client
+		assert.Equal(t, limited, roundTrip(t, dialWebsocket(t, url), subtract))
+	})
+
+	t.Run("requests in flight", func(t *testing.T) {
+		srv := httptest.NewServer(newServer().WithMaxInFlight(1))
+		defer srv.Close()
+		url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+		blocked := dialWebsocket(t, url)
+		require.NoError(t, blocked.WriteMessage(websocket.TextMessage,
+			[]byte(`{"jsonrpc": "2.0", "method": "block", "id": 1}`)))
+
+		conn := dialWebsocket(t, url)
+		require.Eventually(t, func() bool {
+			return strings.Contains(roundTrip(t, conn, subtract), "too many requests in flight")
+		}, 5*time.Second, 10*time.Millisecond)
+
+		close(release)
+		_, resp, err := blocked.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","result":0,"id":1}`, string(resp))
+		assert.Equal(t, `{"jsonrpc":"2.0","result":19,"id":1}`, roundTrip(t, conn, subtract))
+	})
+}
+
+func TestWebsocketShutdown(t *testing.T) {
+	// find a free port to run the server on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, newTestWebsocketOnPort(uint16(port)).Run(ctx))
+
+	var conn *websocket.Conn
+	require.Eventually(t, func() bool {
+		var resp *http.Response
+		conn, resp, err = websocket.DefaultDialer.Dial("ws://"+listener.Addr().String(), nil)
+		if err != nil {
+			return false
+		}
+		return resp.Body.Close() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	defer conn.Close()
+
+	cancel()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+}
@@ -0,0 +1,221 @@
+package l1
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	defaultPollInterval = 12 * time.Second // roughly the L1 block time
+	// defaultConfirmations is the depth after which L1 blocks are final, two epochs
+	defaultConfirmations = 64
+	// maxBlockRange is the maximum number of L1 blocks to query logs for at once
+	maxBlockRange = 2000
+)
+
+// logStateUpdateAbi is the ABI of the event the Starknet core contract emits
+// every time it accepts a state update
+const logStateUpdateAbi = `[{"anonymous":false,"inputs":[
+	{"indexed":false,"internalType":"uint256","name":"globalRoot","type":"uint256"},
+	{"indexed":false,"internalType":"int256","name":"blockNumber","type":"int256"},
+	{"indexed":false,"internalType":"uint256","name":"blockHash","type":"uint256"}
+],"name":"LogStateUpdate","type":"event"}]`
+
+var logStateUpdate = func() abi.Event {
+	parsed, err := abi.JSON(strings.NewReader(logStateUpdateAbi))
+	if err != nil {
+		panic(err)
+	}
+	return parsed.Events["LogStateUpdate"]
+}()
+
+// EthClient is the subset of the Ethereum client API that is needed to follow the
+// Starknet core contract
+type EthClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Client follows the LogStateUpdate events of the Starknet core contract on L1 and
+// records the latest Starknet block accepted on L1 in the Blockchain. Only the logs of L1
+// blocks that are deep enough not to be reorganised are processed.
+type Client struct {
+	ethClient       EthClient
+	blockchain      *blockchain.Blockchain
+	contractAddress common.Address
+	pollInterval    time.Duration
+	confirmations   uint64
+	deploymentBlock uint64
+
+	// nextBlock is the first L1 block whose logs have not been processed yet
+	nextBlock uint64
+
+	log utils.SimpleLogger
+}
+
+func NewClient(ethClient EthClient, bc *blockchain.Blockchain, contractAddress common.Address,
+	log utils.SimpleLogger,
+) *Client {
+	return &Client{
+		ethClient:       ethClient,
+		blockchain:      bc,
+		contractAddress: contractAddress,
+		pollInterval:    defaultPollInterval,
+		confirmations:   defaultConfirmations,
+		log:             log,
+	}
+}
+
+// WithConfirmations sets how many L1 blocks must be built on top of a block before its logs
+// are processed
+func (c *Client) WithConfirmations(confirmations uint64) *Client {
+	c.confirmations = confirmations
+	return c
+}
+
+// WithDeploymentBlock sets the L1 block the core contract was deployed in, which is where
+// processing starts if no L1 block was processed yet
+func (c *Client) WithDeploymentBlock(number uint64) *Client {
+	c.deploymentBlock = number
+	return c
+}
+
+// WithPollInterval sets how often L1 is polled for new state updates
+func (c *Client) WithPollInterval(interval time.Duration) *Client {
+	c.pollInterval = interval
+	return c
+}
+
+// Run polls L1 for new state updates until ctx is cancelled
+func (c *Client) Run(ctx context.Context) error {
+	var err error
+	if c.nextBlock, err = c.startBlock(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.poll(ctx); err != nil && ctx.Err() == nil {
+			c.log.Warnw("Failed fetching state updates from L1", "err", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// startBlock returns the L1 block to start processing logs from: the one after the last
+// processed L1 block, or the deployment block of the core contract if there is none
+func (c *Client) startBlock() (uint64, error) {
+	scanned, err := c.blockchain.L1ScannedHeight()
+	if err == nil {
+		return scanned + 1, nil
+	} else if !errors.Is(err, db.ErrKeyNotFound) {
+		return 0, err
+	}
+
+	// the L1 head was recorded before the processed L1 blocks were
+	head, err := c.blockchain.L1Head()
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return c.deploymentBlock, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return head.L1BlockNumber + 1, nil
+}
+
+// poll processes the logs of the confirmed L1 blocks and records the most recent state
+// update it finds
+func (c *Client) poll(ctx context.Context) error {
+	header, err := c.ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if header.Number.Uint64() < c.confirmations {
+		return nil
+	}
+	latest := header.Number.Uint64() - c.confirmations
+
+	for c.nextBlock <= latest {
+		toBlock := c.nextBlock + maxBlockRange - 1
+		if toBlock > latest {
+			toBlock = latest
+		}
+
+		logs, err := c.ethClient.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(c.nextBlock),
+			ToBlock:   new(big.Int).SetUint64(toBlock),
+			Addresses: []common.Address{c.contractAddress},
+			Topics:    [][]common.Hash{{logStateUpdate.ID}},
+		})
+		if err != nil {
+			return err
+		}
+
+		if last := lastLog(logs); last != nil {
+			head, err := adaptLogStateUpdate(last)
+			if err != nil {
+				return err
+			}
+			if err = c.blockchain.SetL1Head(head); err != nil {
+				return err
+			}
+			c.log.Infow("Block accepted on L1", "number", head.BlockNumber,
+				"hash", head.BlockHash.ShortString(), "l1BlockNumber", head.L1BlockNumber)
+		}
+		if err = c.blockchain.SetL1ScannedHeight(toBlock); err != nil {
+			return err
+		}
+		c.nextBlock = toBlock + 1
+	}
+	return nil
+}
+
+// lastLog returns the last of logs that was not removed by a reorg, if any
+func lastLog(logs []types.Log) *types.Log {
+	for i := len(logs) - 1; i >= 0; i-- {
+		if !logs[i].Removed {
+			return &logs[i]
+		}
+	}
+	return nil
+}
+
+func adaptLogStateUpdate(log *types.Log) (*core.L1Head, error) {
+	values, err := logStateUpdate.Inputs.Unpack(log.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	globalRoot, rootOk := values[0].(*big.Int)
+	blockNumber, numberOk := values[1].(*big.Int)
+	blockHash, hashOk := values[2].(*big.Int)
+	if !rootOk || !numberOk || !hashOk || !blockNumber.IsUint64() {
+		return nil, errors.New("malformed LogStateUpdate event")
+	}
+
+	return &core.L1Head{
+		BlockNumber:   blockNumber.Uint64(),
+		BlockHash:     new(felt.Felt).SetBytes(blockHash.Bytes()),
+		StateRoot:     new(felt.Felt).SetBytes(globalRoot.Bytes()),
+		L1BlockNumber: log.BlockNumber,
+	}, nil
+}
@@ -0,0 +1,201 @@
+package l1
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	ethcore "github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coreContractMock returns the creation code of a contract that emits a LogStateUpdate
+// event whose data is the calldata of every call it receives. Its runtime code is:
+//
+//	CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY
+//	PUSH32 <LogStateUpdate topic> CALLDATASIZE PUSH1 0 LOG1 STOP
+func coreContractMock() []byte {
+	runtime := []byte{0x36, 0x60, 0x00, 0x60, 0x00, 0x37, 0x7f}
+	runtime = append(runtime, logStateUpdate.ID.Bytes()...)
+	runtime = append(runtime, 0x36, 0x60, 0x00, 0xa1, 0x00)
+
+	// PUSH1 len PUSH1 12 PUSH1 0 CODECOPY PUSH1 len PUSH1 0 RETURN
+	size := byte(len(runtime))
+	initCode := []byte{0x60, size, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, size, 0x60, 0x00, 0xf3}
+	return append(initCode, runtime...)
+}
+
+type simulatedL1 struct {
+	*backends.SimulatedBackend
+	auth            *bind.TransactOpts
+	contractAddress common.Address
+}
+
+func newSimulatedL1(t *testing.T) *simulatedL1 {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(t, err)
+
+	backend := backends.NewSimulatedBackend(ethcore.GenesisAlloc{
+		auth.From: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)},
+	}, 8_000_000)
+	t.Cleanup(func() { backend.Close() })
+
+	l1 := &simulatedL1{SimulatedBackend: backend, auth: auth}
+	l1.contractAddress = crypto.CreateAddress(auth.From, 0)
+	l1.sendTransaction(t, nil, coreContractMock())
+	return l1
+}
+
+func (l *simulatedL1) sendTransaction(t *testing.T, to *common.Address, data []byte) {
+	ctx := context.Background()
+	nonce, err := l.PendingNonceAt(ctx, l.auth.From)
+	require.NoError(t, err)
+	gasPrice, err := l.SuggestGasPrice(ctx)
+	require.NoError(t, err)
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: nonce, To: to, Gas: 1_000_000, GasPrice: gasPrice, Data: data})
+	signedTx, err := l.auth.Signer(l.auth.From, tx)
+	require.NoError(t, err)
+	require.NoError(t, l.SendTransaction(ctx, signedTx))
+	l.Commit()
+}
+
+func (l *simulatedL1) logStateUpdate(t *testing.T, head *core.L1Head) {
+	blockHash, stateRoot := head.BlockHash.Bytes(), head.StateRoot.Bytes()
+	data, err := logStateUpdate.Inputs.Pack(
+		new(big.Int).SetBytes(stateRoot[:]),
+		new(big.Int).SetUint64(head.BlockNumber),
+		new(big.Int).SetBytes(blockHash[:]),
+	)
+	require.NoError(t, err)
+	l.sendTransaction(t, &l.contractAddress, data)
+}
+
+func TestClient(t *testing.T) {
+	l1 := newSimulatedL1(t)
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	client := NewClient(l1, chain, l1.contractAddress, utils.NewNopZapLogger()).WithConfirmations(0)
+	ctx := context.Background()
+
+	t.Run("no state updates on L1", func(t *testing.T) {
+		require.NoError(t, client.poll(ctx))
+		_, err := chain.L1Head()
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	newHead := func(number uint64) *core.L1Head {
+		hash, _ := new(felt.Felt).SetRandom()
+		root, _ := new(felt.Felt).SetRandom()
+		return &core.L1Head{BlockNumber: number, BlockHash: hash, StateRoot: root}
+	}
+
+	t.Run("latest state update is recorded", func(t *testing.T) {
+		l1.logStateUpdate(t, newHead(0))
+		head1 := newHead(1)
+		l1.logStateUpdate(t, head1)
+		l1Block, err := l1.HeaderByNumber(ctx, nil)
+		require.NoError(t, err)
+		head1.L1BlockNumber = l1Block.Number.Uint64()
+
+		require.NoError(t, client.poll(ctx))
+		got, err := chain.L1Head()
+		require.NoError(t, err)
+		assert.Equal(t, head1, got)
+	})
+
+	t.Run("logs of other contracts are ignored", func(t *testing.T) {
+		otherChain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+		other := NewClient(l1, otherChain, common.HexToAddress("0x1234"), utils.NewNopZapLogger()).WithConfirmations(0)
+		l1.logStateUpdate(t, newHead(2))
+		// no LogStateUpdate was emitted by 0x1234, so no L1 head is recorded
+		require.NoError(t, other.poll(ctx))
+		_, err := otherChain.L1Head()
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	t.Run("processing resumes after the recorded L1 head", func(t *testing.T) {
+		resumed := NewClient(l1, chain, l1.contractAddress, utils.NewNopZapLogger()).WithConfirmations(0)
+		var err error
+		resumed.nextBlock, err = resumed.startBlock()
+		require.NoError(t, err)
+		require.NoError(t, resumed.poll(ctx))
+
+		got, err := chain.L1Head()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), got.BlockNumber)
+	})
+
+	t.Run("processed L1 blocks are recorded", func(t *testing.T) {
+		l1Block, err := l1.HeaderByNumber(ctx, nil)
+		require.NoError(t, err)
+		scanned, err := chain.L1ScannedHeight()
+		require.NoError(t, err)
+		assert.Equal(t, l1Block.Number.Uint64(), scanned)
+
+		start, err := client.startBlock()
+		require.NoError(t, err)
+		assert.Equal(t, scanned+1, start)
+	})
+
+	t.Run("only confirmed L1 blocks are processed", func(t *testing.T) {
+		confirmed := NewClient(l1, chain, l1.contractAddress, utils.NewNopZapLogger()).WithConfirmations(2)
+		var err error
+		confirmed.nextBlock, err = confirmed.startBlock()
+		require.NoError(t, err)
+
+		head := newHead(3)
+		l1.logStateUpdate(t, head)
+		l1.Commit()
+		require.NoError(t, confirmed.poll(ctx))
+		got, err := chain.L1Head()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), got.BlockNumber)
+
+		l1.Commit()
+		require.NoError(t, confirmed.poll(ctx))
+		got, err = chain.L1Head()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), got.BlockNumber)
+	})
+}
+
+func TestStartBlock(t *testing.T) {
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	client := NewClient(nil, chain, common.Address{}, utils.NewNopZapLogger()).WithDeploymentBlock(100)
+
+	start, err := client.startBlock()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), start)
+
+	hash, _ := new(felt.Felt).SetRandom()
+	root, _ := new(felt.Felt).SetRandom()
+	require.NoError(t, chain.SetL1Head(&core.L1Head{BlockHash: hash, StateRoot: root, L1BlockNumber: 150}))
+	start, err = client.startBlock()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(151), start)
+
+	require.NoError(t, chain.SetL1ScannedHeight(200))
+	start, err = client.startBlock()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(201), start)
+}
+
+func TestLastLog(t *testing.T) {
+	assert.Nil(t, lastLog(nil))
+	logs := []types.Log{{BlockNumber: 1}, {BlockNumber: 2}, {BlockNumber: 3, Removed: true}}
+	assert.Equal(t, &logs[1], lastLog(logs))
+}
@@ -0,0 +1,91 @@
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/sync"
+)
+
+// health reports whether the node is alive and whether it is close enough to the tip of the
+// chain to serve up to date data
+type health struct {
+	chain      *blockchain.Blockchain
+	syncReader sync.Reader
+	sources    *starknetdata.Multi
+	// maxLag is the number of blocks the node can be behind the tip and still be ready
+	maxLag uint64
+}
+
+type healthStatus struct {
+	Healthy  bool   `json:"healthy"`
+	Ready    bool   `json:"ready"`
+	Database string `json:"database"`
+	// CurrentBlock is nil until the first block is stored
+	CurrentBlock *uint64 `json:"current_block"`
+	// HighestBlock is nil until the latest block is fetched from the network
+	HighestBlock *uint64 `json:"highest_block"`
+	Lag          *uint64 `json:"lag"`
+	// Sources is the health of the sources blocks are fetched from
+	Sources []starknetdata.SourceHealth `json:"sources,omitempty"`
+}
+
+func (h *health) status() *healthStatus {
+	status := &healthStatus{Database: "ok"}
+	if height, err := h.chain.Height(); err == nil {
+		status.CurrentBlock = &height
+	} else if !errors.Is(err, db.ErrKeyNotFound) {
+		status.Database = err.Error()
+	}
+	status.Healthy = status.Database == "ok"
+
+	if highest := h.syncReader.HighestBlockHeader(); highest != nil {
+		status.HighestBlock = &highest.Number
+	}
+	if status.CurrentBlock != nil && status.HighestBlock != nil {
+		var lag uint64
+		if *status.HighestBlock > *status.CurrentBlock {
+			lag = *status.HighestBlock - *status.CurrentBlock
+		}
+		status.Lag = &lag
+		status.Ready = status.Healthy && lag <= h.maxLag
+	}
+	if h.sources != nil {
+		status.Sources = h.sources.Health()
+	}
+	return status
+}
+
+// serveHealth answers with [http.StatusOK] as long as the database can be read
+func (h *health) serveHealth(writer http.ResponseWriter, req *http.Request) {
+	status := h.status()
+	writeHealthStatus(writer, req, status, status.Healthy)
+}
+
+// serveReady answers with [http.StatusOK] once the node is at most maxLag blocks behind the
+// latest block known to the network
+func (h *health) serveReady(writer http.ResponseWriter, req *http.Request) {
+	status := h.status()
+	writeHealthStatus(writer, req, status, status.Ready)
+}
+
+func writeHealthStatus(writer http.ResponseWriter, req *http.Request, status *healthStatus, ok bool) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if ok {
+		writer.WriteHeader(http.StatusOK)
+	} else {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if req.Method == http.MethodGet {
+		json.NewEncoder(writer).Encode(status)
+	}
+}
@@ -0,0 +1,102 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/sync"
+	"github.com/NethermindEth/juno/testsource"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSyncReader struct {
+	sync.Reader
+	highest *core.Header
+}
+
+func (r *fakeSyncReader) HighestBlockHeader() *core.Header {
+	return r.highest
+}
+
+func TestHealth(t *testing.T) {
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	gw, closer := testsource.NewTestGateway(utils.MAINNET)
+	defer closer()
+	syncReader := new(fakeSyncReader)
+	h := &health{chain: chain, syncReader: syncReader, maxLag: 2}
+
+	check := func(t *testing.T, serve http.HandlerFunc, wantStatus int) *healthStatus {
+		recorder := httptest.NewRecorder()
+		serve(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, wantStatus, recorder.Code)
+
+		status := new(healthStatus)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), status))
+		return status
+	}
+	store := func(t *testing.T, number uint64) {
+		block, err := gw.BlockByNumber(context.Background(), number)
+		require.NoError(t, err)
+		update, err := gw.StateUpdate(context.Background(), number)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(block, update, nil))
+	}
+
+	t.Run("empty db is healthy but not ready", func(t *testing.T) {
+		status := check(t, h.serveHealth, http.StatusOK)
+		assert.Equal(t, &healthStatus{Healthy: true, Database: "ok"}, status)
+		check(t, h.serveReady, http.StatusServiceUnavailable)
+	})
+
+	store(t, 0)
+	t.Run("not ready until the highest block is known", func(t *testing.T) {
+		check(t, h.serveHealth, http.StatusOK)
+		status := check(t, h.serveReady, http.StatusServiceUnavailable)
+		assert.Nil(t, status.Lag)
+	})
+
+	syncReader.highest = &core.Header{Number: 3}
+	t.Run("not ready while lagging behind", func(t *testing.T) {
+		status := check(t, h.serveReady, http.StatusServiceUnavailable)
+		require.NotNil(t, status.Lag)
+		assert.Equal(t, uint64(3), *status.Lag)
+		assert.False(t, status.Ready)
+	})
+
+	store(t, 1)
+	t.Run("ready within the max lag", func(t *testing.T) {
+		status := check(t, h.serveReady, http.StatusOK)
+		assert.True(t, status.Ready)
+		assert.Equal(t, uint64(1), *status.CurrentBlock)
+		assert.Equal(t, uint64(3), *status.HighestBlock)
+		assert.Equal(t, uint64(2), *status.Lag)
+	})
+
+	t.Run("health of the sources", func(t *testing.T) {
+		h.sources = starknetdata.NewMulti([]starknetdata.Source{{Name: "gateway", StarknetData: gw}})
+		defer func() { h.sources = nil }()
+
+		status := check(t, h.serveHealth, http.StatusOK)
+		assert.Equal(t, []starknetdata.SourceHealth{{Name: "gateway", Healthy: true}}, status.Sources)
+	})
+
+	t.Run("only GET and HEAD are allowed", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.serveReady(recorder, httptest.NewRequest(http.MethodHead, "/", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Empty(t, recorder.Body.Bytes())
+
+		recorder = httptest.NewRecorder()
+		h.serveHealth(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
@@ -0,0 +1,306 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/sync"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "juno"
+
+// metrics collects the statistics of the node and serves them to Prometheus
+type metrics struct {
+	registry *prometheus.Registry
+	http     *http.Server
+	log      utils.Logger
+
+	blocksStored prometheus.Counter
+
+	gatewayRequests *prometheus.CounterVec
+	gatewayLatency  *prometheus.HistogramVec
+	gatewayRetries  *prometheus.CounterVec
+
+	rpcRequests *prometheus.CounterVec
+	rpcLatency  *prometheus.HistogramVec
+}
+
+func newMetrics(chain *blockchain.Blockchain, syncReader sync.Reader, sources *starknetdata.Multi, database *pebble.DB,
+	log utils.Logger,
+) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		log:      log,
+		blocksStored: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "sync",
+			Name:      "blocks_stored_total",
+			Help:      "Number of blocks stored by the synchronizer, its rate is the sync speed in blocks per second.",
+		}),
+		gatewayRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "gateway",
+			Name:      "requests_total",
+			Help:      "Number of requests sent to the feeder gateway.",
+		}, []string{"endpoint", "status"}),
+		gatewayLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "gateway",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken by the requests to the feeder gateway.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		gatewayRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "gateway",
+			Name:      "retries_total",
+			Help:      "Number of failed requests to the feeder gateway that were retried.",
+		}, []string{"endpoint"}),
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "rpc",
+			Name:      "requests_total",
+			Help:      "Number of JSON-RPC requests handled.",
+		}, []string{"method", "status"}),
+		rpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "rpc",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to handle JSON-RPC requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+
+	height := func() float64 {
+		if h, err := chain.Height(); err == nil {
+			return float64(h)
+		}
+		return 0
+	}
+	highest := func() float64 {
+		if header := syncReader.HighestBlockHeader(); header != nil {
+			return float64(header.Number)
+		}
+		return 0
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "sync",
+			Name:      "height",
+			Help:      "Number of the latest stored block.",
+		}, height),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "sync",
+			Name:      "highest_block",
+			Help:      "Number of the latest block known to the network.",
+		}, highest),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "sync",
+			Name:      "head_lag",
+			Help:      "Number of blocks the latest stored block is behind the latest block known to the network.",
+		}, func() float64 {
+			if lag := highest() - height(); lag > 0 {
+				return lag
+			}
+			return 0
+		}),
+		m.blocksStored,
+		m.gatewayRequests,
+		m.gatewayLatency,
+		m.gatewayRetries,
+		m.rpcRequests,
+		m.rpcLatency,
+	)
+	if sources != nil {
+		m.registry.MustRegister(newSourceCollector(sources))
+	}
+	if database != nil {
+		m.registry.MustRegister(newDBCollector(database))
+	}
+
+	m.http = &http.Server{
+		Handler: promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}),
+	}
+	return m
+}
+
+// Run starts to serve the metrics on addr
+func (m *metrics) Run(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := m.http.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			m.log.Warnw("Metrics server stopped", "err", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		if err := m.http.Shutdown(context.Background()); err != nil {
+			m.log.Warnw("Error shutting down the metrics server", "err", err)
+		}
+	}()
+	return nil
+}
+
+// OnBlockStored implements sync.EventListener
+func (m *metrics) OnBlockStored(uint64) {
+	m.blocksStored.Inc()
+}
+
+// OnRequest implements clients.EventListener
+func (m *metrics) OnRequest(endpoint string, took time.Duration, err error) {
+	m.gatewayRequests.WithLabelValues(endpoint, status(err == nil)).Inc()
+	m.gatewayLatency.WithLabelValues(endpoint).Observe(took.Seconds())
+}
+
+// OnRetry implements clients.EventListener
+func (m *metrics) OnRetry(endpoint string) {
+	m.gatewayRetries.WithLabelValues(endpoint).Inc()
+}
+
+// rpcMiddleware counts the JSON-RPC requests and measures how long they take
+func (m *metrics) rpcMiddleware(ctx context.Context, call *jsonrpc.Call, next jsonrpc.CallHandler) (any, *jsonrpc.Error) {
+	start := time.Now()
+	result, err := next(ctx, call)
+
+	method := call.Method
+	if err != nil && err.Code == jsonrpc.MethodNotFound {
+		// clients choose the method names, keep them out of the labels
+		method = "unknown"
+	}
+	m.rpcRequests.WithLabelValues(method, status(err == nil)).Inc()
+	m.rpcLatency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	return result, err
+}
+
+func status(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "error"
+}
+
+// sourceCollector reads the health of the sources every time the metrics are scraped
+type sourceCollector struct {
+	sources *starknetdata.Multi
+
+	healthy    *prometheus.Desc
+	requests   *prometheus.Desc
+	failures   *prometheus.Desc
+	mismatches *prometheus.Desc
+}
+
+func newSourceCollector(sources *starknetdata.Multi) *sourceCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "source", name), help, []string{"source"}, nil)
+	}
+	return &sourceCollector{
+		sources:    sources,
+		healthy:    desc("healthy", "Whether the source is healthy."),
+		requests:   desc("requests_total", "Number of requests sent to the source."),
+		failures:   desc("failures_total", "Number of requests to the source that failed."),
+		mismatches: desc("mismatches_total", "Number of blocks and state updates of the source that other sources disagreed with."),
+	}
+}
+
+func (c *sourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *sourceCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, health := range c.sources.Health() {
+		healthy := 0.0
+		if health.Healthy {
+			healthy = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.healthy, prometheus.GaugeValue, healthy, health.Name)
+		ch <- prometheus.MustNewConstMetric(c.requests, prometheus.CounterValue, float64(health.Requests), health.Name)
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.CounterValue, float64(health.Failures), health.Name)
+		ch <- prometheus.MustNewConstMetric(c.mismatches, prometheus.CounterValue, float64(health.Mismatches), health.Name)
+	}
+}
+
+// dbCollector reads the statistics of the database every time the metrics are scraped
+type dbCollector struct {
+	database *pebble.DB
+
+	diskUsage       *prometheus.Desc
+	cacheSize       *prometheus.Desc
+	cacheHits       *prometheus.Desc
+	cacheMisses     *prometheus.Desc
+	compactions     *prometheus.Desc
+	compactionDebt  *prometheus.Desc
+	flushes         *prometheus.Desc
+	memTableSize    *prometheus.Desc
+	walBytesWritten *prometheus.Desc
+	levelFiles      *prometheus.Desc
+	levelSize       *prometheus.Desc
+}
+
+func newDBCollector(database *pebble.DB) *dbCollector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db", name), help, labels, nil)
+	}
+	return &dbCollector{
+		database:        database,
+		diskUsage:       desc("disk_usage_bytes", "Disk space used by the database."),
+		cacheSize:       desc("block_cache_size_bytes", "Size of the block cache."),
+		cacheHits:       desc("block_cache_hits_total", "Number of block cache hits."),
+		cacheMisses:     desc("block_cache_misses_total", "Number of block cache misses."),
+		compactions:     desc("compactions_total", "Number of compactions."),
+		compactionDebt:  desc("compaction_debt_bytes", "Estimated number of bytes that need to be compacted."),
+		flushes:         desc("flushes_total", "Number of memtable flushes."),
+		memTableSize:    desc("memtable_size_bytes", "Size of the memtables."),
+		walBytesWritten: desc("wal_bytes_written_total", "Number of bytes written to the write-ahead log."),
+		levelFiles:      desc("level_files", "Number of files in an LSM level.", "level"),
+		levelSize:       desc("level_size_bytes", "Size of the files in an LSM level.", "level"),
+	}
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.database.Metrics()
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(c.diskUsage, float64(stats.DiskSpaceUsage()))
+	gauge(c.cacheSize, float64(stats.BlockCache.Size))
+	counter(c.cacheHits, float64(stats.BlockCache.Hits))
+	counter(c.cacheMisses, float64(stats.BlockCache.Misses))
+	counter(c.compactions, float64(stats.Compact.Count))
+	gauge(c.compactionDebt, float64(stats.Compact.EstimatedDebt))
+	counter(c.flushes, float64(stats.Flush.Count))
+	gauge(c.memTableSize, float64(stats.MemTable.Size))
+	counter(c.walBytesWritten, float64(stats.WAL.BytesWritten))
+	for level, levelStats := range stats.Levels {
+		gauge(c.levelFiles, float64(levelStats.NumFiles), strconv.Itoa(level))
+		gauge(c.levelSize, float64(levelStats.Size), strconv.Itoa(level))
+	}
+}
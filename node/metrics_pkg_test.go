@@ -0,0 +1,90 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/sync"
+	"github.com/NethermindEth/juno/testsource"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	testDB := pebble.NewMemTest()
+	chain := blockchain.New(testDB, utils.MAINNET)
+	gw, closer := testsource.NewTestGateway(utils.MAINNET)
+	defer closer()
+	for number := uint64(0); number < 2; number++ {
+		block, err := gw.BlockByNumber(context.Background(), number)
+		require.NoError(t, err)
+		update, err := gw.StateUpdate(context.Background(), number)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(block, update, nil))
+	}
+
+	log := utils.NewNopZapLogger()
+	sources := starknetdata.NewMulti([]starknetdata.Source{{Name: "gateway", StarknetData: gw}})
+	_, err := sources.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	m := newMetrics(chain, sync.NewSynchronizer(chain, gw, log), sources, testDB.(*pebble.DB), log)
+	m.OnBlockStored(0)
+	m.OnBlockStored(1)
+	m.OnRequest("get_block", time.Second, nil)
+	m.OnRequest("get_block", time.Second, errors.New("500 Internal Server Error"))
+	m.OnRetry("get_block")
+
+	rpcServer := jsonrpc.NewServer().WithMiddleware(m.rpcMiddleware)
+	require.NoError(t, rpcServer.RegisterMethod(jsonrpc.Method{
+		Name: "starknet_chainId",
+		Handler: func() (string, *jsonrpc.Error) {
+			return "0x1", nil
+		},
+	}))
+	for _, req := range []string{
+		`{"jsonrpc":"2.0","method":"starknet_chainId","id":1}`,
+		`{"jsonrpc":"2.0","method":"random_name","id":2}`,
+	} {
+		_, err := rpcServer.Handle([]byte(req))
+		require.NoError(t, err)
+	}
+
+	srv := httptest.NewServer(m.http.Handler)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		"juno_sync_height 1",
+		"juno_sync_highest_block 0",
+		"juno_sync_head_lag 0",
+		"juno_sync_blocks_stored_total 2",
+		`juno_gateway_requests_total{endpoint="get_block",status="ok"} 1`,
+		`juno_gateway_requests_total{endpoint="get_block",status="error"} 1`,
+		`juno_gateway_request_duration_seconds_count{endpoint="get_block"} 2`,
+		`juno_gateway_retries_total{endpoint="get_block"} 1`,
+		`juno_rpc_requests_total{method="starknet_chainId",status="ok"} 1`,
+		`juno_rpc_requests_total{method="unknown",status="error"} 1`,
+		`juno_rpc_request_duration_seconds_count{method="starknet_chainId"} 1`,
+		`juno_db_level_files{level="0"}`,
+		"juno_db_disk_usage_bytes",
+		`juno_source_healthy{source="gateway"} 1`,
+		`juno_source_requests_total{source="gateway"} 1`,
+		`juno_source_failures_total{source="gateway"} 0`,
+	} {
+		assert.Contains(t, string(body), line)
+	}
+}
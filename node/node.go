@@ -2,18 +2,23 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"path/filepath"
+	"strings"
+	stdsync "sync"
 	"time"
 
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/l1"
 	"github.com/NethermindEth/juno/rpc"
-	"github.com/NethermindEth/juno/starknetdata/gateway"
 	"github.com/NethermindEth/juno/sync"
 	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type StarknetNode interface {
@@ -29,16 +34,39 @@ const (
 	defaultMetricsPort = ":9090"
 
 	shutdownTimeout = 5 * time.Second
+
+	// rpcGzipMinSize is the size from which RPC responses are compressed, which are then
+	// mostly blocks and state updates
+	rpcGzipMinSize = 1024
 )
 
 // Config is the top-level juno configuration.
 type Config struct {
-	Verbosity    utils.LogLevel `mapstructure:"verbosity"`
-	RpcPort      uint16         `mapstructure:"rpc-port"`
-	Metrics      bool           `mapstructure:"metrics"`
-	DatabasePath string         `mapstructure:"db-path"`
-	Network      utils.Network  `mapstructure:"network"`
-	EthNode      string         `mapstructure:"eth-node"`
+	Verbosity             utils.LogLevel `mapstructure:"verbosity"`
+	RpcHost               string         `mapstructure:"rpc-host"`
+	RpcPort               uint16         `mapstructure:"rpc-port"`
+	Ws                    bool           `mapstructure:"ws"`
+	WsPort                uint16         `mapstructure:"ws-port"`
+	RpcTlsCert            string         `mapstructure:"rpc-tls-cert"`
+	RpcTlsKey             string         `mapstructure:"rpc-tls-key"`
+	RpcCorsOrigins        []string       `mapstructure:"rpc-cors-origins"`
+	RpcMaxBodySize        uint           `mapstructure:"rpc-max-body-size"`
+	RpcMaxBatchSize       uint           `mapstructure:"rpc-max-batch-size"`
+	RpcRateLimit          uint           `mapstructure:"rpc-rate-limit"`
+	RpcMaxInFlight        uint           `mapstructure:"rpc-max-in-flight"`
+	ReadyMaxLag           uint           `mapstructure:"ready-max-lag"`
+	IpcPath               string         `mapstructure:"ipc-path"`
+	SyncMaxLookahead      uint           `mapstructure:"sync-max-lookahead"`
+	SyncMaxLookaheadBytes uint           `mapstructure:"sync-max-lookahead-bytes"`
+	Sources               []string       `mapstructure:"source"`
+	SourceQuorum          uint           `mapstructure:"source-quorum"`
+	Metrics               bool           `mapstructure:"metrics"`
+	DatabasePath          string         `mapstructure:"db-path"`
+	Network               utils.Network  `mapstructure:"network"`
+	EthNode               string         `mapstructure:"eth-node"`
+
+	RpcTimeout        time.Duration            `mapstructure:"rpc-timeout"`
+	RpcMethodTimeouts map[string]time.Duration `mapstructure:"rpc-method-timeouts"`
 }
 
 type Node struct {
@@ -46,7 +74,13 @@ type Node struct {
 	db           db.DB
 	blockchain   *blockchain.Blockchain
 	synchronizer *sync.Synchronizer
+	l1Client     *l1.Client
+	ethClient    *ethclient.Client
 	http         *jsonrpc.Http
+	ws           *jsonrpc.Websocket
+	ipc          *jsonrpc.Ipc
+	sources      *sources
+	metrics      *metrics
 
 	log utils.Logger
 }
@@ -58,12 +92,20 @@ func New(cfg *Config) (StarknetNode, error) {
 	if !cfg.Verbosity.IsValid() {
 		return nil, utils.ErrUnknownLogLevel
 	}
-	if cfg.DatabasePath == "" {
+	if (cfg.RpcTlsCert == "") != (cfg.RpcTlsKey == "") {
+		return nil, errors.New("the TLS certificate and key must be set together")
+	}
+	if cfg.DatabasePath == "" || cfg.IpcPath == "" {
 		dirPrefix, err := utils.DefaultDataDir()
 		if err != nil {
 			return nil, err
 		}
-		cfg.DatabasePath = filepath.Join(dirPrefix, cfg.Network.String())
+		if cfg.DatabasePath == "" {
+			cfg.DatabasePath = filepath.Join(dirPrefix, cfg.Network.String())
+		}
+		if cfg.IpcPath == "" {
+			cfg.IpcPath = filepath.Join(dirPrefix, cfg.Network.String()+".ipc")
+		}
 	}
 	log, err := utils.NewZapLogger(cfg.Verbosity)
 	if err != nil {
@@ -73,32 +115,166 @@ func New(cfg *Config) (StarknetNode, error) {
 	if err != nil {
 		return nil, err
 	}
+	var ethClient *ethclient.Client
+	if cfg.EthNode != "" {
+		if ethClient, err = ethclient.Dial(cfg.EthNode); err != nil {
+			return nil, fmt.Errorf("connect to the ethereum node: %w", err)
+		}
+	}
+	nodeSources, err := makeSources(cfg, log)
+	if err != nil {
+		closeEthClient(ethClient)
+		return nil, err
+	}
 	stateDb, err := pebble.New(cfg.DatabasePath, dbLog)
 	if err != nil {
+		closeEthClient(ethClient)
+		nodeSources.Close()
 		return nil, err
 	}
 
 	chain := blockchain.New(stateDb, cfg.Network)
-	synchronizer := sync.NewSynchronizer(chain, gateway.NewGateway(cfg.Network), log)
+	synchronizer := sync.NewSynchronizer(chain, nodeSources.Multi, log).
+		WithLookahead(uint64(cfg.SyncMaxLookahead), uint64(cfg.SyncMaxLookaheadBytes))
+
+	var l1Client *l1.Client
+	if ethClient != nil {
+		l1Client = l1.NewClient(ethClient, chain, cfg.Network.CoreContractAddress(), log).
+			WithDeploymentBlock(cfg.Network.CoreContractDeploymentBlock())
+	}
+
+	rpcMiddlewares := []jsonrpc.Middleware{jsonrpc.LogRequests(log)}
+	var nodeMetrics *metrics
+	if cfg.Metrics {
+		pebbleDb, _ := stateDb.(*pebble.DB)
+		nodeMetrics = newMetrics(chain, synchronizer, nodeSources.Multi, pebbleDb, log)
+		nodeSources.WithListener(nodeMetrics)
+		synchronizer.WithListener(nodeMetrics)
+		rpcMiddlewares = append(rpcMiddlewares, nodeMetrics.rpcMiddleware)
+	}
+
+	rpcHandler := rpc.New(chain, synchronizer, cfg.Network.ChainId())
+	nodeHealth := &health{
+		chain:      chain,
+		syncReader: synchronizer,
+		sources:    nodeSources.Multi,
+		maxLag:     uint64(cfg.ReadyMaxLag),
+	}
+	rpcHttp := makeHttp(cfg, rpcHandler, rpcMiddlewares, log).
+		WithHandler("/health", http.HandlerFunc(nodeHealth.serveHealth)).
+		WithHandler("/ready", http.HandlerFunc(nodeHealth.serveReady))
+	var rpcWs *jsonrpc.Websocket
+	if cfg.Ws {
+		rpcWs = makeWebsocket(cfg, rpcHandler, rpcMiddlewares, log)
+	}
 	return &Node{
 		cfg:          cfg,
 		log:          log,
 		db:           stateDb,
 		blockchain:   chain,
 		synchronizer: synchronizer,
-		http:         makeHttp(cfg.RpcPort, rpc.New(chain, cfg.Network.ChainId()), log),
+		l1Client:     l1Client,
+		ethClient:    ethClient,
+		http:         rpcHttp,
+		ws:           rpcWs,
+		ipc:          makeIpc(cfg, rpcHandler, rpcMiddlewares, log),
+		sources:      nodeSources,
+		metrics:      nodeMetrics,
 	}, nil
 }
 
-func makeHttp(port uint16, rpcHandler *rpc.Handler, log utils.Logger) *jsonrpc.Http {
-	return jsonrpc.NewHttp(port, []jsonrpc.Method{
-		{"starknet_chainId", nil, rpcHandler.ChainId},
-		{"starknet_blockNumber", nil, rpcHandler.BlockNumber},
-		{"starknet_blockHashAndNumber", nil, rpcHandler.BlockNumberAndHash},
-		{"starknet_getBlockWithTxHashes", []jsonrpc.Parameter{{Name: "block_id"}}, rpcHandler.GetBlockWithTxHashes},
-		{"starknet_getBlockWithTxs", []jsonrpc.Parameter{{Name: "block_id"}}, rpcHandler.GetBlockWithTxs},
-		{"starknet_getTransactionByHash", []jsonrpc.Parameter{{Name: "transaction_hash"}}, rpcHandler.GetTransactionByHash},
-	}, log)
+// openRPCInfo describes the API served on the root path, which is the oldest supported
+// version so that clients that predate the versioned paths keep working
+var openRPCInfo = jsonrpc.OpenRPCInfo{Title: "Juno Starknet RPC", Version: rpc.SpecVersionV0_2}
+
+// makeHttp creates the HTTP transport, a zero limit in cfg means no limit
+func makeHttp(cfg *Config, rpcHandler *rpc.Handler, middlewares []jsonrpc.Middleware, log utils.Logger) *jsonrpc.Http {
+	httpTransport := jsonrpc.NewHttp(cfg.RpcPort, rpcHandler.MethodsV0_2(), log).
+		WithHost(cfg.RpcHost).
+		WithTLS(cfg.RpcTlsCert, cfg.RpcTlsKey).
+		WithCORS(cfg.RpcCorsOrigins).
+		WithGzip(rpcGzipMinSize).
+		WithVersion("/v0_2", rpc.SpecVersionV0_2, rpcHandler.MethodsV0_2()).
+		WithVersion("/v0_3", rpc.SpecVersionV0_3, rpcHandler.MethodsV0_3()).
+		WithMiddleware(middlewares...).
+		WithDiscovery(openRPCInfo).
+		WithMaxBodySize(int64(cfg.RpcMaxBodySize)).
+		WithMaxBatchSize(int(cfg.RpcMaxBatchSize)).
+		WithRateLimit(cfg.RpcRateLimit).
+		WithMaxInFlight(cfg.RpcMaxInFlight).
+		WithTimeout(cfg.RpcTimeout)
+	for method, timeout := range methodTimeouts(cfg, rpcHandler) {
+		httpTransport.WithMethodTimeout(method, timeout)
+	}
+	return httpTransport
+}
+
+// makeWebsocket creates the WebSocket transport with the same limits as the HTTP one, where
+// the body size limit applies to every message
+func makeWebsocket(cfg *Config, rpcHandler *rpc.Handler, middlewares []jsonrpc.Middleware, log utils.Logger) *jsonrpc.Websocket {
+	ws := jsonrpc.NewWebsocket(cfg.WsPort, rpcHandler.MethodsV0_2(), log).
+		WithHost(cfg.RpcHost).
+		WithTLS(cfg.RpcTlsCert, cfg.RpcTlsKey).
+		WithCORS(cfg.RpcCorsOrigins).
+		WithVersion("/v0_2", rpc.SpecVersionV0_2, rpcHandler.MethodsV0_2()).
+		WithVersion("/v0_3", rpc.SpecVersionV0_3, rpcHandler.MethodsV0_3()).
+		WithMiddleware(middlewares...).
+		WithDiscovery(openRPCInfo).
+		WithMaxMessageSize(int64(cfg.RpcMaxBodySize)).
+		WithMaxBatchSize(int(cfg.RpcMaxBatchSize)).
+		WithRateLimit(cfg.RpcRateLimit).
+		WithMaxInFlight(cfg.RpcMaxInFlight).
+		WithSubscriptions(subscriptionMethods, topics(rpcHandler)).
+		WithTimeout(cfg.RpcTimeout)
+	for method, timeout := range methodTimeouts(cfg, rpcHandler) {
+		ws.WithMethodTimeout(method, timeout)
+	}
+	return ws
+}
+
+// makeIpc creates the IPC transport, which serves the latest version of the API since it
+// has no clients that predate the versioned paths
+func makeIpc(cfg *Config, rpcHandler *rpc.Handler, middlewares []jsonrpc.Middleware, log utils.Logger) *jsonrpc.Ipc {
+	ipc := jsonrpc.NewIpc(cfg.IpcPath, rpcHandler.MethodsV0_3(), log).
+		WithMiddleware(middlewares...).
+		WithDiscovery(jsonrpc.OpenRPCInfo{Title: openRPCInfo.Title, Version: rpc.SpecVersionV0_3}).
+		WithSubscriptions(subscriptionMethods, topics(rpcHandler)).
+		WithTimeout(cfg.RpcTimeout)
+	for method, timeout := range methodTimeouts(cfg, rpcHandler) {
+		ipc.WithMethodTimeout(method, timeout)
+	}
+	return ipc
+}
+
+// methodTimeouts returns the per-method deadlines of cfg keyed by the names the methods are
+// registered with. The names are matched regardless of case because viper lowercases the
+// keys of maps read from config files.
+func methodTimeouts(cfg *Config, rpcHandler *rpc.Handler) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(cfg.RpcMethodTimeouts))
+	for name, timeout := range cfg.RpcMethodTimeouts {
+		for _, method := range rpcHandler.MethodsV0_3() {
+			if strings.EqualFold(method.Name, name) {
+				name = method.Name
+				break
+			}
+		}
+		timeouts[name] = timeout
+	}
+	return timeouts
+}
+
+var subscriptionMethods = jsonrpc.SubscriptionMethods{
+	Subscribe:    "starknet_subscribe",
+	Unsubscribe:  "starknet_unsubscribe",
+	Notification: "starknet_subscription",
+}
+
+func topics(rpcHandler *rpc.Handler) []jsonrpc.Topic {
+	return []jsonrpc.Topic{
+		{"newHeads", rpcHandler.SubscribeNewHeads},
+		{"events", rpcHandler.SubscribeEvents},
+		{"transactionStatus", rpcHandler.SubscribeTransactionStatus},
+	}
 }
 
 func (n *Node) Run(ctx context.Context) (err error) {
@@ -108,13 +284,57 @@ func (n *Node) Run(ctx context.Context) (err error) {
 		if closeErr := n.db.Close(); closeErr != nil {
 			err = closeErr
 		}
+		if closeErr := n.sources.Close(); closeErr != nil {
+			n.log.Warnw("Error closing the sources", "err", closeErr)
+		}
+		closeEthClient(n.ethClient)
 	}()
 	go func() {
 		<-ctx.Done()
 		n.log.Infow("Shutting down Juno...")
 	}()
-	n.http.Run(ctx)
-	return n.synchronizer.Run(ctx)
+
+	var wg stdsync.WaitGroup
+	defer wg.Wait()
+	// cancelled before waiting, so that the L1 client stops when Run returns early because
+	// a listener failed to start
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if n.l1Client != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.l1Client.Run(runCtx); err != nil {
+				n.log.Errorw("L1 client stopped", "err", err)
+			}
+		}()
+	} else {
+		n.log.Warnw("Ethereum node is not set, blocks will not be reported as accepted on L1")
+	}
+
+	if err = n.http.Run(runCtx); err != nil {
+		return err
+	}
+	if n.ws != nil {
+		if err = n.ws.Run(runCtx); err != nil {
+			return err
+		}
+	}
+	if err = n.ipc.Run(runCtx); err != nil {
+		return err
+	}
+	if n.metrics != nil {
+		if err = n.metrics.Run(runCtx, defaultMetricsPort); err != nil {
+			return err
+		}
+	}
+	return n.synchronizer.Run(runCtx)
+}
+
+func closeEthClient(ethClient *ethclient.Client) {
+	if ethClient != nil {
+		ethClient.Close()
+	}
 }
 
 func (n *Node) Config() Config {
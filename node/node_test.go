@@ -3,8 +3,10 @@ package node_test
 import (
 	"context"
 	"fmt"
+	"net"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/NethermindEth/juno/node"
 	"github.com/NethermindEth/juno/utils"
@@ -48,6 +50,7 @@ func TestNew(t *testing.T) {
 				expectedCfg := node.Config{
 					Network:      n,
 					DatabasePath: filepath.Join(defaultDataDir, n.String()),
+					IpcPath:      filepath.Join(defaultDataDir, n.String()+".ipc"),
 				}
 				snNode, err := node.New(cfg)
 				require.NoError(t, err)
@@ -59,4 +62,76 @@ func TestNew(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("eth-node", func(t *testing.T) {
+		cfg := &node.Config{Network: utils.MAINNET, DatabasePath: t.TempDir(), EthNode: "http://localhost:8545"}
+		snNode, err := node.New(cfg)
+		require.NoError(t, err)
+		require.NoError(t, snNode.Run(ctx))
+
+		cfg = &node.Config{Network: utils.MAINNET, DatabasePath: t.TempDir(), EthNode: "unknown://localhost:8545"}
+		_, err = node.New(cfg)
+		assert.Error(t, err)
+	})
+	t.Run("ws", func(t *testing.T) {
+		cfg := &node.Config{Network: utils.MAINNET, DatabasePath: t.TempDir(), Ws: true, RpcRateLimit: 10}
+		snNode, err := node.New(cfg)
+		require.NoError(t, err)
+		require.NoError(t, snNode.Run(ctx))
+	})
+	t.Run("listener failure with eth-node", func(t *testing.T) {
+		busy, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer busy.Close()
+
+		cfg := &node.Config{
+			Network:      utils.MAINNET,
+			DatabasePath: t.TempDir(),
+			EthNode:      "http://localhost:8545",
+			RpcHost:      "127.0.0.1",
+			RpcPort:      uint16(busy.Addr().(*net.TCPAddr).Port),
+		}
+		snNode, err := node.New(cfg)
+		require.NoError(t, err)
+
+		runCtx, runCancel := context.WithCancel(context.Background())
+		defer runCancel()
+		runErr := make(chan error, 1)
+		go func() { runErr <- snNode.Run(runCtx) }()
+		select {
+		case err = <-runErr:
+			assert.Error(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after the RPC listener failed")
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		cfg := &node.Config{Network: utils.MAINNET, DatabasePath: t.TempDir(), RpcTlsCert: "cert.pem"}
+		_, err := node.New(cfg)
+		assert.Error(t, err)
+
+		cfg = &node.Config{Network: utils.MAINNET, DatabasePath: t.TempDir(), RpcTlsKey: "key.pem"}
+		_, err = node.New(cfg)
+		assert.Error(t, err)
+	})
+	t.Run("sources", func(t *testing.T) {
+		cfg := &node.Config{
+			Network:      utils.MAINNET,
+			DatabasePath: t.TempDir(),
+			Sources:      []string{"../testsource/testdata/mainnet", "https://alpha-mainnet.starknet.io/feeder_gateway"},
+			SourceQuorum: 2,
+		}
+		snNode, err := node.New(cfg)
+		require.NoError(t, err)
+		require.NoError(t, snNode.Run(ctx))
+
+		cfg = &node.Config{Network: utils.MAINNET, DatabasePath: t.TempDir(), Sources: []string{t.TempDir()}}
+		_, err = node.New(cfg)
+		assert.Error(t, err)
+
+		cfg = &node.Config{Network: utils.MAINNET, DatabasePath: t.TempDir(), SourceQuorum: 2}
+		_, err = node.New(cfg)
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,86 @@
+package node
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/NethermindEth/juno/clients"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/starknetdata/archive"
+	"github.com/NethermindEth/juno/starknetdata/gateway"
+	"github.com/NethermindEth/juno/utils"
+)
+
+// multiSourceMaxRetries is the number of times a request to a gateway is retried before the next
+// source is tried. A single gateway is retried for much longer since there is nothing else to
+// fall back to.
+const multiSourceMaxRetries = 3
+
+// sources are the StarknetData the node syncs from
+type sources struct {
+	*starknetdata.Multi
+	clients  []*clients.GatewayClient
+	archives []*archive.Archive
+}
+
+// makeSources creates the sources listed in the config, or the feeder gateway of the network if
+// there are none. Entries starting with http:// or https:// are the URLs of feeder gateways, the
+// others are paths to archives.
+func makeSources(cfg *Config, log utils.SimpleLogger) (*sources, error) {
+	locations := cfg.Sources
+	if len(locations) == 0 {
+		locations = []string{cfg.Network.URL()}
+	}
+	if int(cfg.SourceQuorum) > len(locations) {
+		return nil, errors.New("the source quorum is larger than the number of sources")
+	}
+
+	s := new(sources)
+	multiSources := make([]starknetdata.Source, 0, len(locations))
+	for _, location := range locations {
+		if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+			if !strings.HasSuffix(location, "/") {
+				location += "/"
+			}
+			client := clients.NewGatewayClient(location)
+			if len(locations) > 1 {
+				client.WithMaxRetries(multiSourceMaxRetries)
+			}
+			s.clients = append(s.clients, client)
+			multiSources = append(multiSources, starknetdata.Source{
+				Name:         location,
+				StarknetData: gateway.NewGatewayWithClient(client),
+			})
+			continue
+		}
+
+		sourceArchive, err := archive.New(location)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.archives = append(s.archives, sourceArchive)
+		multiSources = append(multiSources, starknetdata.Source{Name: location, StarknetData: sourceArchive})
+	}
+
+	s.Multi = starknetdata.NewMulti(multiSources).WithQuorum(int(cfg.SourceQuorum)).WithLogger(log)
+	return s, nil
+}
+
+// WithListener sets the listener of all the gateway clients
+func (s *sources) WithListener(listener clients.EventListener) {
+	for _, client := range s.clients {
+		client.WithListener(listener)
+	}
+}
+
+// Close closes the archives
+func (s *sources) Close() error {
+	var err error
+	for _, sourceArchive := range s.archives {
+		if closeErr := sourceArchive.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -39,11 +39,15 @@ type BlockNumberAndHash struct {
 }
 
 // https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L814
+//
+// In addition to the tags defined by the specification, the "l1_accepted" tag refers to
+// the latest block that has been accepted on L1.
 type BlockId struct {
-	Pending bool
-	Latest  bool
-	Hash    *felt.Felt
-	Number  uint64
+	Pending    bool
+	Latest     bool
+	L1Accepted bool
+	Hash       *felt.Felt
+	Number     uint64
 }
 
 func (b *BlockId) UnmarshalJSON(data []byte) error {
@@ -51,6 +55,8 @@ func (b *BlockId) UnmarshalJSON(data []byte) error {
 		b.Latest = true
 	} else if "\"pending\"" == string(data) {
 		b.Pending = true
+	} else if "\"l1_accepted\"" == string(data) {
+		b.L1Accepted = true
 	} else {
 		jsonObject := make(map[string]json.RawMessage)
 		if err := json.Unmarshal(data, &jsonObject); err != nil {
@@ -75,10 +81,10 @@ func (b *BlockId) UnmarshalJSON(data []byte) error {
 
 // https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1072
 type BlockHeader struct {
-	Hash             *felt.Felt `json:"block_hash"`
+	Hash             *felt.Felt `json:"block_hash,omitempty"`
 	ParentHash       *felt.Felt `json:"parent_hash"`
-	Number           uint64     `json:"block_number"`
-	NewRoot          *felt.Felt `json:"new_root"`
+	Number           *uint64    `json:"block_number,omitempty"`
+	NewRoot          *felt.Felt `json:"new_root,omitempty"`
 	Timestamp        uint64     `json:"timestamp"`
 	SequencerAddress *felt.Felt `json:"sequencer_address,omitempty"`
 }
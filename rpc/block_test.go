@@ -26,6 +26,12 @@ func TestBlockId(t *testing.T) {
 				Pending: true,
 			},
 		},
+		"l1_accepted": {
+			blockIdJson: "\"l1_accepted\"",
+			expectedBlockId: rpc.BlockId{
+				L1Accepted: true,
+			},
+		},
 		"number": {
 			blockIdJson: `{ "block_number" : 123123 }`,
 			expectedBlockId: rpc.BlockId{
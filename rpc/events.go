@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1889
+type EventsArg struct {
+	EventFilter
+	ResultPageRequest
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1901
+type EventFilter struct {
+	FromBlock *BlockId     `json:"from_block"`
+	ToBlock   *BlockId     `json:"to_block"`
+	Address   *felt.Felt   `json:"address"`
+	Keys      []*felt.Felt `json:"keys"`
+}
+
+// EventsArgV0_3 is the argument of starknet_getEvents in the v0.3 API
+//
+// https://github.com/starkware-libs/starknet-specs/blob/v0.3.0/api/starknet_api_openrpc.json
+type EventsArgV0_3 struct {
+	EventFilterV0_3
+	ResultPageRequest
+}
+
+// EventFilterV0_3 is the event filter of the v0.3 API, it differs from [EventFilter] in Keys,
+// where Keys[i] holds the values accepted for the i-th key of an event.
+type EventFilterV0_3 struct {
+	FromBlock *BlockId       `json:"from_block"`
+	ToBlock   *BlockId       `json:"to_block"`
+	Address   *felt.Felt     `json:"address"`
+	Keys      [][]*felt.Felt `json:"keys"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1931
+type ResultPageRequest struct {
+	ContinuationToken string `json:"continuation_token"`
+	ChunkSize         uint64 `json:"chunk_size"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1044
+type EmittedEvent struct {
+	*Event
+	BlockHash       *felt.Felt `json:"block_hash,omitempty"`
+	BlockNumber     *uint64    `json:"block_number,omitempty"`
+	TransactionHash *felt.Felt `json:"transaction_hash"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L458
+type EventsChunk struct {
+	Events            []*EmittedEvent `json:"events"`
+	ContinuationToken string          `json:"continuation_token,omitempty"`
+}
+
+// continuationToken points to the next event to return: the number of the block
+// that includes it and its index among the events emitted in that block.
+type continuationToken struct {
+	blockNumber uint64
+	index       uint64
+}
+
+func (t *continuationToken) String() string {
+	return fmt.Sprintf("%d-%d", t.blockNumber, t.index)
+}
+
+func (t *continuationToken) FromString(str string) error {
+	blockNumber, index, found := strings.Cut(str, "-")
+	if !found {
+		return fmt.Errorf("invalid continuation token %q", str)
+	}
+
+	var err error
+	if t.blockNumber, err = strconv.ParseUint(blockNumber, 10, 64); err != nil {
+		return err
+	}
+	t.index, err = strconv.ParseUint(index, 10, 64)
+	return err
+}
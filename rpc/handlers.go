@@ -1,30 +1,46 @@
 package rpc
 
 import (
+	"encoding/hex"
 	"errors"
+	"sort"
 
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/sync"
 )
 
 var (
-	ErrBlockNotFound   = &jsonrpc.Error{Code: 24, Message: "Block not found"}
-	ErrTxnHashNotFound = &jsonrpc.Error{Code: 25, Message: "Transaction hash not found"}
-	ErrNoBlock         = &jsonrpc.Error{Code: 32, Message: "There are no blocks"}
+	ErrContractNotFound         = &jsonrpc.Error{Code: 20, Message: "Contract not found"}
+	ErrBlockNotFound            = &jsonrpc.Error{Code: 24, Message: "Block not found"}
+	ErrTxnHashNotFound          = &jsonrpc.Error{Code: 25, Message: "Transaction hash not found"}
+	ErrClassHashNotFound        = &jsonrpc.Error{Code: 28, Message: "Class hash not found"}
+	ErrPageSizeTooBig           = &jsonrpc.Error{Code: 31, Message: "Requested page size is too big"}
+	ErrNoBlock                  = &jsonrpc.Error{Code: 32, Message: "There are no blocks"}
+	ErrInvalidContinuationToken = &jsonrpc.Error{Code: 33, Message: "The supplied continuation token is invalid or unknown"}
+	ErrHistoryUnavailable       = &jsonrpc.Error{Code: jsonrpc.InternalError, Message: "State history is not available for the requested block"}
 )
 
+const maxEventChunkSize = 1024
+
+// maxEventBlocks is the number of blocks a single starknet_getEvents call looks at before it
+// returns a continuation token
+const maxEventBlocks = 10_000
+
 type Handler struct {
-	bcReader blockchain.Reader
+	bcReader   blockchain.Reader
+	syncReader sync.Reader
 
 	chainId *felt.Felt
 }
 
-func New(bcReader blockchain.Reader, chainId *felt.Felt) *Handler {
+func New(bcReader blockchain.Reader, syncReader sync.Reader, chainId *felt.Felt) *Handler {
 	return &Handler{
-		bcReader: bcReader,
-		chainId:  chainId,
+		bcReader:   bcReader,
+		syncReader: syncReader,
+		chainId:    chainId,
 	}
 }
 
@@ -61,17 +77,38 @@ func (h *Handler) GetBlockWithTxHashes(id *BlockId) (*BlockWithTxHashes, *jsonrp
 	}
 
 	return &BlockWithTxHashes{
-		Status:      BlockStatusAcceptedL2, // todo
+		Status:      h.blockStatus(id, &block.Header),
 		BlockHeader: adaptBlockHeader(&block.Header),
 		TxnHashes:   txnHashes,
 	}, nil
 }
 
+func (h *Handler) blockStatus(id *BlockId, header *core.Header) BlockStatus {
+	if id.Pending {
+		return BlockStatusPending
+	} else if h.isAcceptedOnL1(header.Number) {
+		return BlockStatusAcceptedL1
+	}
+	return BlockStatusAcceptedL2
+}
+
+// isAcceptedOnL1 reports whether the block with the given number has been accepted on L1
+func (h *Handler) isAcceptedOnL1(blockNumber uint64) bool {
+	head, err := h.bcReader.L1Head()
+	return err == nil && blockNumber <= head.BlockNumber
+}
+
 func adaptBlockHeader(header *core.Header) BlockHeader {
+	var number *uint64
+	// the hash and number of the pending block are not known yet
+	if header.Hash != nil {
+		number = &header.Number
+	}
+
 	return BlockHeader{
 		Hash:             header.Hash,
 		ParentHash:       header.ParentHash,
-		Number:           header.Number,
+		Number:           number,
 		NewRoot:          header.GlobalStateRoot,
 		Timestamp:        header.Timestamp,
 		SequencerAddress: header.SequencerAddress,
@@ -90,7 +127,7 @@ func (h *Handler) GetBlockWithTxs(id *BlockId) (*BlockWithTxs, *jsonrpc.Error) {
 	}
 
 	return &BlockWithTxs{
-		Status:       BlockStatusAcceptedL2, // todo
+		Status:       h.blockStatus(id, &block.Header),
 		BlockHeader:  adaptBlockHeader(&block.Header),
 		Transactions: txs,
 	}, nil
@@ -197,7 +234,15 @@ func (h *Handler) getBlockById(id *BlockId) (*core.Block, error) {
 	if id.Latest {
 		block, err = h.bcReader.Head()
 	} else if id.Pending {
-		err = errors.New("pending block is not supported yet")
+		var pending *blockchain.Pending
+		if pending, err = h.bcReader.Pending(); err == nil {
+			block = pending.Block
+		}
+	} else if id.L1Accepted {
+		var head *core.L1Head
+		if head, err = h.bcReader.L1Head(); err == nil {
+			block, err = h.bcReader.GetBlockByNumber(head.BlockNumber)
+		}
 	} else if id.Hash != nil {
 		block, err = h.bcReader.GetBlockByHash(id.Hash)
 	} else {
@@ -211,7 +256,553 @@ func (h *Handler) getBlockById(id *BlockId) (*core.Block, error) {
 func (h *Handler) GetTransactionByHash(hash *felt.Felt) (*Transaction, *jsonrpc.Error) {
 	txn, err := h.bcReader.GetTransactionByHash(hash)
 	if err != nil {
-		return nil, ErrTxnHashNotFound
+		pending, pendingErr := h.bcReader.Pending()
+		if pendingErr != nil {
+			return nil, ErrTxnHashNotFound
+		}
+		var found bool
+		if txn, _, found = pending.Transaction(hash); !found {
+			return nil, ErrTxnHashNotFound
+		}
 	}
 	return adaptTransaction(txn), nil
 }
+
+// GetStateUpdate returns the state update identified by the given BlockId.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L136
+func (h *Handler) GetStateUpdate(id *BlockId) (*StateUpdate, *jsonrpc.Error) {
+	update, rpcErr := h.stateUpdateByID(id)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return adaptStateUpdate(update), nil
+}
+
+// GetStateUpdateV0_3 returns the state update identified by the given BlockId in the
+// layout of the v0.3 API.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/v0.3.0/api/starknet_api_openrpc.json
+func (h *Handler) GetStateUpdateV0_3(id *BlockId) (*StateUpdateV0_3, *jsonrpc.Error) {
+	update, rpcErr := h.stateUpdateByID(id)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	adapted := adaptStateUpdate(update)
+	return &StateUpdateV0_3{
+		BlockHash: adapted.BlockHash,
+		NewRoot:   adapted.NewRoot,
+		OldRoot:   adapted.OldRoot,
+		StateDiff: &StateDiffV0_3{
+			StorageDiffs:              adapted.StateDiff.StorageDiffs,
+			DeprecatedDeclaredClasses: adapted.StateDiff.DeclaredContracts,
+			// only Cairo 0 classes are known, which are all deprecated declarations
+			DeclaredClasses:   []DeclaredClass{},
+			DeployedContracts: adapted.StateDiff.DeployedContracts,
+			ReplacedClasses:   []ReplacedClass{},
+			Nonces:            adapted.StateDiff.Nonces,
+		},
+	}, nil
+}
+
+func (h *Handler) stateUpdateByID(id *BlockId) (*core.StateUpdate, *jsonrpc.Error) {
+	var update *core.StateUpdate
+	var err error
+	if id.Latest {
+		if height, heightErr := h.bcReader.Height(); heightErr != nil {
+			err = heightErr
+		} else {
+			update, err = h.bcReader.GetStateUpdateByNumber(height)
+		}
+	} else if id.Pending {
+		var pending *blockchain.Pending
+		if pending, err = h.bcReader.Pending(); err == nil {
+			update = pending.StateUpdate
+		}
+	} else if id.L1Accepted {
+		var head *core.L1Head
+		if head, err = h.bcReader.L1Head(); err == nil {
+			update, err = h.bcReader.GetStateUpdateByNumber(head.BlockNumber)
+		}
+	} else if id.Hash != nil {
+		update, err = h.bcReader.GetStateUpdateByHash(id.Hash)
+	} else {
+		update, err = h.bcReader.GetStateUpdateByNumber(id.Number)
+	}
+
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+	return update, nil
+}
+
+func adaptStateUpdate(update *core.StateUpdate) *StateUpdate {
+	nonces := make([]Nonce, 0, len(update.StateDiff.Nonces))
+	for addr, nonce := range update.StateDiff.Nonces {
+		addr := addr
+		nonces = append(nonces, Nonce{ContractAddress: &addr, Nonce: nonce})
+	}
+	// the diffs are kept in maps, sort them for the responses to be deterministic
+	sort.Slice(nonces, func(i, j int) bool {
+		return nonces[i].ContractAddress.Cmp(nonces[j].ContractAddress) < 0
+	})
+
+	storageDiffs := make([]StorageDiff, 0, len(update.StateDiff.StorageDiffs))
+	for addr, diffs := range update.StateDiff.StorageDiffs {
+		addr := addr
+		entries := make([]Entry, len(diffs))
+		for index, diff := range diffs {
+			entries[index] = Entry{Key: diff.Key, Value: diff.Value}
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Key.Cmp(entries[j].Key) < 0
+		})
+		storageDiffs = append(storageDiffs, StorageDiff{Address: &addr, StorageEntries: entries})
+	}
+	sort.Slice(storageDiffs, func(i, j int) bool {
+		return storageDiffs[i].Address.Cmp(storageDiffs[j].Address) < 0
+	})
+
+	deployedContracts := make([]DeployedContract, len(update.StateDiff.DeployedContracts))
+	for index, deployedContract := range update.StateDiff.DeployedContracts {
+		deployedContracts[index] = DeployedContract{
+			Address:   deployedContract.Address,
+			ClassHash: deployedContract.ClassHash,
+		}
+	}
+	sort.Slice(deployedContracts, func(i, j int) bool {
+		return deployedContracts[i].Address.Cmp(deployedContracts[j].Address) < 0
+	})
+
+	declaredContracts := update.StateDiff.DeclaredClasses
+	if declaredContracts == nil {
+		declaredContracts = []*felt.Felt{}
+	}
+
+	return &StateUpdate{
+		BlockHash: update.BlockHash,
+		NewRoot:   update.NewRoot,
+		OldRoot:   update.OldRoot,
+		StateDiff: &StateDiff{
+			StorageDiffs:      storageDiffs,
+			DeclaredContracts: declaredContracts,
+			DeployedContracts: deployedContracts,
+			Nonces:            nonces,
+		},
+	}
+}
+
+// GetStorageAt gets the value of the storage at the given address and key as of the given block.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L179
+func (h *Handler) GetStorageAt(address, key *felt.Felt, id *BlockId) (*felt.Felt, *jsonrpc.Error) {
+	if id.Pending {
+		pending, err := h.bcReader.Pending()
+		if err != nil {
+			return nil, ErrBlockNotFound
+		}
+		if value, found := pending.ContractStorage(address, key); found {
+			return value, nil
+		}
+	}
+
+	blockNumber, err := h.blockNumberById(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	value, err := h.bcReader.ContractStorageAt(address, key, blockNumber)
+	if errors.Is(err, core.ErrHistoryUnavailable) {
+		return nil, ErrHistoryUnavailable
+	} else if err != nil {
+		return nil, ErrContractNotFound
+	}
+	return value, nil
+}
+
+// blockNumberById returns the number of the block identified by id. The pending block
+// builds on top of the latest block, so the latest block number is returned for it.
+func (h *Handler) blockNumberById(id *BlockId) (uint64, error) {
+	if id.Latest || id.Pending {
+		return h.bcReader.Height()
+	} else if id.Hash != nil {
+		header, err := h.bcReader.GetBlockHeaderByHash(id.Hash)
+		if err != nil {
+			return 0, err
+		}
+		return header.Number, nil
+	}
+
+	number := id.Number
+	if id.L1Accepted {
+		head, err := h.bcReader.L1Head()
+		if err != nil {
+			return 0, err
+		}
+		number = head.BlockNumber
+	}
+
+	height, err := h.bcReader.Height()
+	if err != nil {
+		return 0, err
+	} else if number > height {
+		return 0, errors.New("block number is higher than the chain height")
+	}
+	return number, nil
+}
+
+// GetTransactionReceiptByHash returns the receipt of a transaction identified by the given hash.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L222
+func (h *Handler) GetTransactionReceiptByHash(hash *felt.Felt) (TransactionReceipt, *jsonrpc.Error) {
+	var receipt *core.TransactionReceipt
+	var blockHash *felt.Felt
+	var blockNumber *uint64
+	var status TxnStatus
+
+	txn, err := h.bcReader.GetTransactionByHash(hash)
+	if err == nil {
+		var number uint64
+		if receipt, blockHash, number, err = h.bcReader.GetReceipt(hash); err != nil {
+			return nil, ErrTxnHashNotFound
+		}
+		blockNumber = &number
+
+		status = TxnStatusAcceptedL2
+		if h.isAcceptedOnL1(number) {
+			status = TxnStatusAcceptedL1
+		}
+	} else {
+		pending, pendingErr := h.bcReader.Pending()
+		if pendingErr != nil {
+			return nil, ErrTxnHashNotFound
+		}
+		var found bool
+		if txn, receipt, found = pending.Transaction(hash); !found {
+			return nil, ErrTxnHashNotFound
+		}
+		status = TxnStatusPending
+	}
+
+	messages := make([]*MsgToL1, len(receipt.L2ToL1Message))
+	for idx, msg := range receipt.L2ToL1Message {
+		messages[idx] = &MsgToL1{
+			To:      new(felt.Felt).SetBytes(msg.To.Bytes()),
+			Payload: msg.Payload,
+		}
+	}
+
+	events := make([]*Event, len(receipt.Events))
+	for idx, event := range receipt.Events {
+		events[idx] = &Event{
+			From: event.From,
+			Keys: event.Keys,
+			Data: event.Data,
+		}
+	}
+
+	fee := receipt.Fee
+	if fee == nil {
+		// receipts of early blocks do not report a fee
+		fee = new(felt.Felt)
+	}
+
+	common := CommonReceiptProperties{
+		Type:         adaptTransaction(txn).Type,
+		Hash:         txn.Hash(),
+		ActualFee:    fee,
+		Status:       status,
+		BlockHash:    blockHash,
+		BlockNumber:  blockNumber,
+		MessagesSent: messages,
+		Events:       events,
+	}
+	switch v := txn.(type) {
+	case *core.InvokeTransaction:
+		return &InvokeTransactionReceipt{CommonReceiptProperties: common}, nil
+	case *core.DeclareTransaction:
+		return &DeclareTransactionReceipt{CommonReceiptProperties: common}, nil
+	case *core.DeployTransaction:
+		return &DeployTransactionReceipt{CommonReceiptProperties: common, ContractAddress: v.ContractAddress}, nil
+	case *core.DeployAccountTransaction:
+		return &DeployAccountTransactionReceipt{CommonReceiptProperties: common, ContractAddress: v.ContractAddress}, nil
+	case *core.L1HandlerTransaction:
+		return &L1HandlerTransactionReceipt{
+			CommonReceiptProperties: common,
+			MessageHash:             "0x" + hex.EncodeToString(v.MessageHash()),
+		}, nil
+	default:
+		panic("not a transaction")
+	}
+}
+
+// GetEvents returns the events matching the given filter, a chunk at a time.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L433
+func (h *Handler) GetEvents(args *EventsArg) (*EventsChunk, *jsonrpc.Error) {
+	return h.events(args.FromBlock, args.ToBlock, &blockchain.EventFilter{
+		Address: args.Address,
+		Keys:    args.Keys,
+	}, &args.ResultPageRequest)
+}
+
+// GetEventsV0_3 returns the events matching the given filter of the v0.3 API, which matches
+// event keys by their position.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/v0.3.0/api/starknet_api_openrpc.json
+func (h *Handler) GetEventsV0_3(args *EventsArgV0_3) (*EventsChunk, *jsonrpc.Error) {
+	return h.events(args.FromBlock, args.ToBlock, &blockchain.EventFilter{
+		Address:        args.Address,
+		PositionalKeys: args.Keys,
+	}, &args.ResultPageRequest)
+}
+
+// events returns a chunk of the events matching filter between the given blocks
+func (h *Handler) events(fromBlock, toBlock *BlockId, filter *blockchain.EventFilter,
+	args *ResultPageRequest,
+) (*EventsChunk, *jsonrpc.Error) {
+	if args.ChunkSize == 0 {
+		return nil, &jsonrpc.Error{Code: jsonrpc.InvalidParams, Message: "chunk_size must be greater than zero"}
+	} else if args.ChunkSize > maxEventChunkSize {
+		return nil, ErrPageSizeTooBig
+	}
+
+	height, err := h.bcReader.Height()
+	if err != nil {
+		return &EventsChunk{Events: []*EmittedEvent{}}, nil
+	}
+
+	filter.FromBlock, filter.ToBlock = 0, height
+	if fromBlock != nil {
+		if filter.FromBlock, err = h.eventsBlockNumberById(fromBlock); err != nil {
+			return nil, ErrBlockNotFound
+		}
+	}
+	if toBlock != nil {
+		if filter.ToBlock, err = h.eventsBlockNumberById(toBlock); err != nil {
+			return nil, ErrBlockNotFound
+		}
+	}
+	if filter.FromBlock > filter.ToBlock {
+		return &EventsChunk{Events: []*EmittedEvent{}}, nil
+	}
+
+	start := blockchain.EventPosition{BlockNumber: filter.FromBlock}
+	if args.ContinuationToken != "" {
+		var token continuationToken
+		if err = token.FromString(args.ContinuationToken); err != nil ||
+			token.blockNumber < filter.FromBlock || token.blockNumber > filter.ToBlock {
+			return nil, ErrInvalidContinuationToken
+		}
+		start = blockchain.EventPosition{BlockNumber: token.blockNumber, Index: token.index}
+	}
+
+	filteredEvents, next, err := h.bcReader.Events(filter, start, args.ChunkSize, maxEventBlocks)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.InternalError, Message: err.Error()}
+	}
+
+	events := make([]*EmittedEvent, len(filteredEvents))
+	for i, filteredEvent := range filteredEvents {
+		events[i] = adaptFilteredEvent(filteredEvent)
+	}
+
+	chunk := &EventsChunk{Events: events}
+	if next != nil {
+		chunk.ContinuationToken = (&continuationToken{blockNumber: next.BlockNumber, index: next.Index}).String()
+	}
+	return chunk, nil
+}
+
+func adaptFilteredEvent(filteredEvent *blockchain.FilteredEvent) *EmittedEvent {
+	event := &EmittedEvent{
+		Event: &Event{
+			From: filteredEvent.From,
+			Keys: filteredEvent.Keys,
+			Data: filteredEvent.Data,
+		},
+		BlockHash:       filteredEvent.BlockHash,
+		TransactionHash: filteredEvent.TransactionHash,
+	}
+	// events of the pending block have neither a block hash nor a block number
+	if filteredEvent.BlockHash != nil {
+		blockNumber := filteredEvent.BlockNumber
+		event.BlockNumber = &blockNumber
+	}
+	return event
+}
+
+// eventsBlockNumberById is like blockNumberById except that the pending block is
+// treated as if it had the number height+1, which is how [blockchain.Blockchain.Events]
+// refers to it.
+func (h *Handler) eventsBlockNumberById(id *BlockId) (uint64, error) {
+	if id.Pending {
+		height, err := h.bcReader.Height()
+		if err != nil {
+			return 0, err
+		}
+		return height + 1, nil
+	}
+	return h.blockNumberById(id)
+}
+
+// GetNonce returns the nonce of the contract at the given address as of the given block.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L634
+func (h *Handler) GetNonce(id *BlockId, address *felt.Felt) (*felt.Felt, *jsonrpc.Error) {
+	if id.Pending {
+		pending, err := h.bcReader.Pending()
+		if err != nil {
+			return nil, ErrBlockNotFound
+		}
+		if nonce, found := pending.ContractNonce(address); found {
+			return nonce, nil
+		}
+	}
+
+	blockNumber, err := h.blockNumberById(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	nonce, err := h.bcReader.ContractNonceAt(address, blockNumber)
+	if errors.Is(err, core.ErrHistoryUnavailable) {
+		return nil, ErrHistoryUnavailable
+	} else if err != nil {
+		return nil, ErrContractNotFound
+	}
+	return nonce, nil
+}
+
+// GetClassHashAt returns the class hash of the contract at the given address as of the given block.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L320
+func (h *Handler) GetClassHashAt(id *BlockId, address *felt.Felt) (*felt.Felt, *jsonrpc.Error) {
+	if id.Pending {
+		pending, err := h.bcReader.Pending()
+		if err != nil {
+			return nil, ErrBlockNotFound
+		}
+		if classHash, found := pending.ContractClassHash(address); found {
+			return classHash, nil
+		}
+	}
+
+	blockNumber, err := h.blockNumberById(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	classHash, err := h.bcReader.ContractClassHashAt(address, blockNumber)
+	if errors.Is(err, core.ErrHistoryUnavailable) {
+		return nil, ErrHistoryUnavailable
+	} else if err != nil {
+		return nil, ErrContractNotFound
+	}
+	return classHash, nil
+}
+
+// GetClass returns the class with the given class hash.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L268
+func (h *Handler) GetClass(id *BlockId, classHash *felt.Felt) (*Class, *jsonrpc.Error) {
+	if id.Pending {
+		pending, err := h.bcReader.Pending()
+		if err != nil {
+			return nil, ErrBlockNotFound
+		}
+		if class, found := pending.Class(classHash); found {
+			return adaptClass(class), nil
+		}
+	}
+
+	blockNumber, err := h.blockNumberById(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	class, err := h.bcReader.ClassAt(classHash, blockNumber)
+	if err != nil {
+		return nil, ErrClassHashNotFound
+	}
+	return adaptClass(class), nil
+}
+
+// GetClassAt returns the class of the contract at the given address as of the given block.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L370
+func (h *Handler) GetClassAt(id *BlockId, address *felt.Felt) (*Class, *jsonrpc.Error) {
+	classHash, rpcErr := h.GetClassHashAt(id, address)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return h.GetClass(id, classHash)
+}
+
+func adaptClass(class *core.Class) *Class {
+	adaptEntryPoints := func(entryPoints []core.EntryPoint) []EntryPoint {
+		adapted := make([]EntryPoint, len(entryPoints))
+		for i, entryPoint := range entryPoints {
+			adapted[i] = EntryPoint{Offset: entryPoint.Offset, Selector: entryPoint.Selector}
+		}
+		return adapted
+	}
+
+	return &Class{
+		Program: class.Program,
+		EntryPoints: EntryPoints{
+			Constructor: adaptEntryPoints(class.Constructors),
+			External:    adaptEntryPoints(class.Externals),
+			L1Handler:   adaptEntryPoints(class.L1Handlers),
+		},
+		Abi: class.Abi,
+	}
+}
+
+// Syncing returns the progress of the synchronisation with the network. If the node has
+// caught up with the latest block of the network, false is returned.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+func (h *Handler) Syncing() (*Sync, *jsonrpc.Error) {
+	notSyncing := &Sync{Syncing: false}
+	if h.syncReader == nil {
+		return notSyncing, nil
+	}
+
+	startingNumber, started := h.syncReader.StartingBlockNumber()
+	highest := h.syncReader.HighestBlockHeader()
+	if !started || highest == nil {
+		return notSyncing, nil
+	}
+	head, err := h.bcReader.Head()
+	if err == nil && head.Number >= highest.Number {
+		return notSyncing, nil
+	}
+
+	status := &Sync{
+		Syncing:             true,
+		StartingBlockNumber: NumAsHex(startingNumber),
+		HighestBlockHash:    highest.Hash,
+		HighestBlockNumber:  NumAsHex(highest.Number),
+	}
+	if starting, err := h.bcReader.GetBlockByNumber(startingNumber); err == nil {
+		status.StartingBlockHash = starting.Hash
+	}
+	if head != nil {
+		status.CurrentBlockHash = head.Hash
+		status.CurrentBlockNumber = NumAsHex(head.Number)
+	}
+	return status, nil
+}
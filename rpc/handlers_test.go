@@ -10,6 +10,7 @@ import (
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/jsonrpc"
 	"github.com/NethermindEth/juno/rpc"
 	"github.com/NethermindEth/juno/starknetdata"
 	"github.com/NethermindEth/juno/sync"
@@ -21,7 +22,11 @@ import (
 
 func TestHandler(t *testing.T) {
 	bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
-	handler := rpc.New(bc, utils.MAINNET.ChainId())
+	log := utils.NewNopZapLogger()
+	gw, closer := testsource.NewTestGateway(utils.MAINNET)
+	defer closer()
+	synchronizer := sync.NewSynchronizer(bc, testsource.StubClasses(gw), log).WithPendingPollInterval(100 * time.Millisecond)
+	handler := rpc.New(bc, synchronizer, utils.MAINNET.ChainId())
 
 	t.Run("starknet_chainId", func(t *testing.T) {
 		cId, err := handler.ChainId()
@@ -41,11 +46,16 @@ func TestHandler(t *testing.T) {
 		_, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Number: 0})
 		assert.Equal(t, rpc.ErrBlockNotFound, err)
 	})
+	t.Run("empty bc - starknet_getStateUpdate", func(t *testing.T) {
+		_, err := handler.GetStateUpdate(&rpc.BlockId{Latest: true})
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+	})
+	t.Run("starknet_syncing before sync started", func(t *testing.T) {
+		status, err := handler.Syncing()
+		require.Nil(t, err)
+		assert.False(t, status.Syncing)
+	})
 
-	log := utils.NewNopZapLogger()
-	gw, closer := testsource.NewTestGateway(utils.MAINNET)
-	defer closer()
-	synchronizer := sync.NewSynchronizer(bc, gw, log)
 	ctx, canceler := context.WithCancel(context.Background())
 
 	syncNodeChan := make(chan struct{})
@@ -54,7 +64,15 @@ func TestHandler(t *testing.T) {
 		close(syncNodeChan)
 	}()
 
-	time.Sleep(time.Second)
+	// the pending block is only stored once the chain is synced up to the tip
+	require.Eventually(t, func() bool {
+		head, err := bc.Head()
+		if err != nil {
+			return false
+		}
+		pending, err := bc.Pending()
+		return err == nil && head.Hash.Equal(pending.Block.ParentHash)
+	}, 30*time.Second, 10*time.Millisecond)
 
 	t.Run("starknet_blockNumber", func(t *testing.T) {
 		num, err := handler.BlockNumber()
@@ -74,10 +92,10 @@ func TestHandler(t *testing.T) {
 	t.Run("starknet_getBlockWithTxHashes", func(t *testing.T) {
 		latestRpc, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Latest: true})
 		assert.Nil(t, err)
-		gwBlock, gwErr := gw.BlockByNumber(ctx, latestRpc.Number)
+		gwBlock, gwErr := gw.BlockByNumber(ctx, *latestRpc.Number)
 		assert.NoError(t, gwErr)
 
-		assert.Equal(t, gwBlock.Number, latestRpc.Number)
+		assert.Equal(t, gwBlock.Number, *latestRpc.Number)
 		assert.Equal(t, gwBlock.Hash, latestRpc.Hash)
 		assert.Equal(t, gwBlock.GlobalStateRoot, latestRpc.NewRoot)
 		assert.Equal(t, gwBlock.ParentHash, latestRpc.ParentHash)
@@ -90,7 +108,7 @@ func TestHandler(t *testing.T) {
 		byHash, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Hash: latestRpc.Hash})
 		require.Nil(t, err)
 		assert.Equal(t, latestRpc, byHash)
-		byNumber, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Number: latestRpc.Number})
+		byNumber, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Number: *latestRpc.Number})
 		require.Nil(t, err)
 		assert.Equal(t, latestRpc, byNumber)
 	})
@@ -111,6 +129,433 @@ func TestHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("starknet_getStateUpdate", func(t *testing.T) {
+		latestUpdate, err := handler.GetStateUpdate(&rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+		latestBlock, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+		gwUpdate, gwErr := gw.StateUpdate(ctx, *latestBlock.Number)
+		require.NoError(t, gwErr)
+
+		assert.Equal(t, gwUpdate.BlockHash, latestUpdate.BlockHash)
+		assert.Equal(t, gwUpdate.NewRoot, latestUpdate.NewRoot)
+		assert.Equal(t, gwUpdate.OldRoot, latestUpdate.OldRoot)
+		assert.Equal(t, len(gwUpdate.StateDiff.StorageDiffs), len(latestUpdate.StateDiff.StorageDiffs))
+		for i, diff := range latestUpdate.StateDiff.StorageDiffs {
+			if i > 0 {
+				assert.Negative(t, latestUpdate.StateDiff.StorageDiffs[i-1].Address.Cmp(diff.Address))
+			}
+			gwValues := make(map[felt.Felt]*felt.Felt)
+			for _, gwDiff := range gwUpdate.StateDiff.StorageDiffs[*diff.Address] {
+				gwValues[*gwDiff.Key] = gwDiff.Value
+			}
+			require.Equal(t, len(gwValues), len(diff.StorageEntries))
+			for j, entry := range diff.StorageEntries {
+				if j > 0 {
+					assert.Negative(t, diff.StorageEntries[j-1].Key.Cmp(entry.Key))
+				}
+				assert.Equal(t, gwValues[*entry.Key], entry.Value)
+			}
+		}
+		assert.Equal(t, len(gwUpdate.StateDiff.Nonces), len(latestUpdate.StateDiff.Nonces))
+		for i, nonce := range latestUpdate.StateDiff.Nonces {
+			if i > 0 {
+				assert.Negative(t, latestUpdate.StateDiff.Nonces[i-1].ContractAddress.Cmp(nonce.ContractAddress))
+			}
+			assert.Equal(t, gwUpdate.StateDiff.Nonces[*nonce.ContractAddress], nonce.Nonce)
+		}
+		gwClassHashes := make(map[felt.Felt]*felt.Felt)
+		for _, deployed := range gwUpdate.StateDiff.DeployedContracts {
+			gwClassHashes[*deployed.Address] = deployed.ClassHash
+		}
+		require.Equal(t, len(gwClassHashes), len(latestUpdate.StateDiff.DeployedContracts))
+		for i, deployed := range latestUpdate.StateDiff.DeployedContracts {
+			if i > 0 {
+				assert.Negative(t, latestUpdate.StateDiff.DeployedContracts[i-1].Address.Cmp(deployed.Address))
+			}
+			assert.Equal(t, gwClassHashes[*deployed.Address], deployed.ClassHash)
+		}
+
+		byHash, err := handler.GetStateUpdate(&rpc.BlockId{Hash: latestUpdate.BlockHash})
+		require.Nil(t, err)
+		assert.Equal(t, latestUpdate.BlockHash, byHash.BlockHash)
+		byNumber, err := handler.GetStateUpdate(&rpc.BlockId{Number: *latestBlock.Number})
+		require.Nil(t, err)
+		assert.Equal(t, latestUpdate.BlockHash, byNumber.BlockHash)
+
+		_, err = handler.GetStateUpdate(&rpc.BlockId{Number: *latestBlock.Number + 100})
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+	})
+
+	t.Run("starknet_getStateUpdate v0.3", func(t *testing.T) {
+		latestUpdate, err := handler.GetStateUpdate(&rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+		latestUpdateV0_3, err := handler.GetStateUpdateV0_3(&rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+
+		assert.Equal(t, latestUpdate.BlockHash, latestUpdateV0_3.BlockHash)
+		assert.Equal(t, latestUpdate.NewRoot, latestUpdateV0_3.NewRoot)
+		assert.Equal(t, latestUpdate.OldRoot, latestUpdateV0_3.OldRoot)
+		assert.ElementsMatch(t, latestUpdate.StateDiff.StorageDiffs, latestUpdateV0_3.StateDiff.StorageDiffs)
+		assert.ElementsMatch(t, latestUpdate.StateDiff.Nonces, latestUpdateV0_3.StateDiff.Nonces)
+		assert.Equal(t, latestUpdate.StateDiff.DeployedContracts, latestUpdateV0_3.StateDiff.DeployedContracts)
+		assert.Equal(t, latestUpdate.StateDiff.DeclaredContracts, latestUpdateV0_3.StateDiff.DeprecatedDeclaredClasses)
+		assert.Empty(t, latestUpdateV0_3.StateDiff.DeclaredClasses)
+		assert.Empty(t, latestUpdateV0_3.StateDiff.ReplacedClasses)
+
+		head, err := handler.BlockNumber()
+		require.Nil(t, err)
+		_, err = handler.GetStateUpdateV0_3(&rpc.BlockId{Number: head + 100})
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+	})
+
+	t.Run("starknet_getStorageAt", func(t *testing.T) {
+		latestBlock, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+		gwUpdate, gwErr := gw.StateUpdate(ctx, *latestBlock.Number)
+		require.NoError(t, gwErr)
+
+		for addr, diffs := range gwUpdate.StateDiff.StorageDiffs {
+			addr := addr
+			for _, diff := range diffs {
+				value, err := handler.GetStorageAt(&addr, diff.Key, &rpc.BlockId{Latest: true})
+				require.Nil(t, err)
+				assert.Equal(t, diff.Value, value)
+
+				value, err = handler.GetStorageAt(&addr, diff.Key, &rpc.BlockId{Hash: latestBlock.Hash})
+				require.Nil(t, err)
+				assert.Equal(t, diff.Value, value)
+			}
+		}
+
+		unknownAddr, _ := new(felt.Felt).SetRandom()
+		_, err = handler.GetStorageAt(unknownAddr, &felt.Zero, &rpc.BlockId{Latest: true})
+		assert.Equal(t, rpc.ErrContractNotFound, err)
+		_, err = handler.GetStorageAt(unknownAddr, &felt.Zero, &rpc.BlockId{Number: *latestBlock.Number + 1})
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+	})
+
+	t.Run("starknet_getClassHashAt and starknet_getNonce", func(t *testing.T) {
+		latestBlock, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+		gwUpdate, gwErr := gw.StateUpdate(ctx, 0)
+		require.NoError(t, gwErr)
+
+		for _, deployed := range gwUpdate.StateDiff.DeployedContracts {
+			classHash, err := handler.GetClassHashAt(&rpc.BlockId{Latest: true}, deployed.Address)
+			require.Nil(t, err)
+			assert.Equal(t, deployed.ClassHash, classHash)
+
+			classHash, err = handler.GetClassHashAt(&rpc.BlockId{Number: 0}, deployed.Address)
+			require.Nil(t, err)
+			assert.Equal(t, deployed.ClassHash, classHash)
+
+			nonce, err := handler.GetNonce(&rpc.BlockId{Number: 0}, deployed.Address)
+			require.Nil(t, err)
+			assert.Equal(t, &felt.Zero, nonce)
+		}
+
+		unknownAddr, _ := new(felt.Felt).SetRandom()
+		_, err = handler.GetClassHashAt(&rpc.BlockId{Latest: true}, unknownAddr)
+		assert.Equal(t, rpc.ErrContractNotFound, err)
+		_, err = handler.GetNonce(&rpc.BlockId{Latest: true}, unknownAddr)
+		assert.Equal(t, rpc.ErrContractNotFound, err)
+		_, err = handler.GetClassAt(&rpc.BlockId{Latest: true}, unknownAddr)
+		assert.Equal(t, rpc.ErrContractNotFound, err)
+		_, err = handler.GetNonce(&rpc.BlockId{Number: *latestBlock.Number + 1}, unknownAddr)
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+	})
+
+	t.Run("starknet_getClass", func(t *testing.T) {
+		unknownClassHash, _ := new(felt.Felt).SetRandom()
+		_, err := handler.GetClass(&rpc.BlockId{Latest: true}, unknownClassHash)
+		assert.Equal(t, rpc.ErrClassHashNotFound, err)
+		_, err = handler.GetClass(&rpc.BlockId{Hash: unknownClassHash}, unknownClassHash)
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+	})
+
+	t.Run("starknet_getTransactionReceipt", func(t *testing.T) {
+		_, err := handler.GetTransactionReceiptByHash(new(felt.Felt).SetUint64(0x1234))
+		assert.Equal(t, rpc.ErrTxnHashNotFound, err)
+
+		latestBlock, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+		gwBlock, gwErr := gw.BlockByNumber(ctx, *latestBlock.Number)
+		require.NoError(t, gwErr)
+
+		for i, gwReceipt := range gwBlock.Receipts {
+			typedReceipt, err := handler.GetTransactionReceiptByHash(gwReceipt.TransactionHash)
+			require.Nil(t, err)
+			receipt := typedReceipt.Common()
+
+			txn, err := handler.GetTransactionByHash(gwReceipt.TransactionHash)
+			require.Nil(t, err)
+
+			assert.Equal(t, txn.Type, receipt.Type)
+			assert.Equal(t, gwBlock.Transactions[i].Hash(), receipt.Hash)
+			assert.Equal(t, gwBlock.Hash, receipt.BlockHash)
+			assert.Equal(t, gwBlock.Number, *receipt.BlockNumber)
+			assert.Equal(t, rpc.TxnStatusAcceptedL2, receipt.Status)
+			if gwReceipt.Fee != nil {
+				assert.Equal(t, gwReceipt.Fee, receipt.ActualFee)
+			}
+			switch typedReceipt := typedReceipt.(type) {
+			case *rpc.DeployTransactionReceipt:
+				assert.Equal(t, "DEPLOY", txn.Type)
+				assert.Equal(t, txn.ContractAddress, typedReceipt.ContractAddress)
+			case *rpc.DeployAccountTransactionReceipt:
+				assert.Equal(t, "DEPLOY_ACCOUNT", txn.Type)
+				assert.Equal(t, txn.ContractAddress, typedReceipt.ContractAddress)
+			case *rpc.InvokeTransactionReceipt:
+				assert.Equal(t, "INVOKE", txn.Type)
+			default:
+				assert.Failf(t, "unexpected receipt", "%T for a %s transaction", typedReceipt, txn.Type)
+			}
+
+			require.Equal(t, len(gwReceipt.Events), len(receipt.Events))
+			for j, event := range gwReceipt.Events {
+				assert.Equal(t, event.From, receipt.Events[j].From)
+				assert.Equal(t, event.Keys, receipt.Events[j].Keys)
+				assert.Equal(t, event.Data, receipt.Events[j].Data)
+			}
+			require.Equal(t, len(gwReceipt.L2ToL1Message), len(receipt.MessagesSent))
+			for j, msg := range gwReceipt.L2ToL1Message {
+				assert.Equal(t, msg.Payload, receipt.MessagesSent[j].Payload)
+			}
+		}
+	})
+
+	t.Run("starknet_getEvents", func(t *testing.T) {
+		args := &rpc.EventsArg{ResultPageRequest: rpc.ResultPageRequest{ChunkSize: 1025}}
+		_, err := handler.GetEvents(args)
+		assert.Equal(t, rpc.ErrPageSizeTooBig, err)
+
+		args.ChunkSize = 10
+		args.ContinuationToken = "invalid"
+		_, err = handler.GetEvents(args)
+		assert.Equal(t, rpc.ErrInvalidContinuationToken, err)
+
+		args.FromBlock = &rpc.BlockId{Number: 1}
+		args.ContinuationToken = "0-0"
+		_, err = handler.GetEvents(args)
+		assert.Equal(t, rpc.ErrInvalidContinuationToken, err)
+
+		args.ContinuationToken = ""
+		args.ToBlock = &rpc.BlockId{Hash: new(felt.Felt).SetUint64(0xdead)}
+		_, err = handler.GetEvents(args)
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+
+		// first mainnet blocks do not emit any events
+		args.ToBlock = &rpc.BlockId{Latest: true}
+		chunk, err := handler.GetEvents(args)
+		require.Nil(t, err)
+		assert.Empty(t, chunk.Events)
+		assert.Empty(t, chunk.ContinuationToken)
+	})
+
+	t.Run("starknet_syncing", func(t *testing.T) {
+		t.Run("caught up with the network", func(t *testing.T) {
+			status, err := handler.Syncing()
+			require.Nil(t, err)
+			assert.False(t, status.Syncing)
+
+			statusJSON, jsonErr := json.Marshal(status)
+			require.NoError(t, jsonErr)
+			assert.JSONEq(t, "false", string(statusJSON))
+		})
+
+		t.Run("behind the network", func(t *testing.T) {
+			highestHash, _ := new(felt.Felt).SetRandom()
+			syncReader := &fakeSyncReader{
+				startingBlockNumber: 1,
+				highestBlockHeader:  &core.Header{Number: 10, Hash: highestHash},
+			}
+			status, err := rpc.New(bc, syncReader, utils.MAINNET.ChainId()).Syncing()
+			require.Nil(t, err)
+
+			block1, bcErr := bc.GetBlockByNumber(1)
+			require.NoError(t, bcErr)
+			head, bcErr := bc.Head()
+			require.NoError(t, bcErr)
+			assert.Equal(t, &rpc.Sync{
+				Syncing:             true,
+				StartingBlockHash:   block1.Hash,
+				StartingBlockNumber: 1,
+				CurrentBlockHash:    head.Hash,
+				CurrentBlockNumber:  rpc.NumAsHex(head.Number),
+				HighestBlockHash:    highestHash,
+				HighestBlockNumber:  10,
+			}, status)
+
+			statusJSON, jsonErr := json.Marshal(status)
+			require.NoError(t, jsonErr)
+			assert.JSONEq(t, `{
+				"starting_block_hash": "`+block1.Hash.String()+`",
+				"starting_block_num": "0x1",
+				"current_block_hash": "`+head.Hash.String()+`",
+				"current_block_num": "0x2",
+				"highest_block_hash": "`+highestHash.String()+`",
+				"highest_block_num": "0xa"
+			}`, string(statusJSON))
+		})
+	})
+
+	t.Run("l1 accepted", func(t *testing.T) {
+		l1AcceptedId := &rpc.BlockId{L1Accepted: true}
+		_, err := handler.GetBlockWithTxHashes(l1AcceptedId)
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+		_, err = handler.GetStateUpdate(l1AcceptedId)
+		assert.Equal(t, rpc.ErrBlockNotFound, err)
+
+		block1, gwErr := gw.BlockByNumber(ctx, 1)
+		require.NoError(t, gwErr)
+		require.NoError(t, bc.SetL1Head(&core.L1Head{
+			BlockNumber: block1.Number,
+			BlockHash:   block1.Hash,
+			StateRoot:   block1.GlobalStateRoot,
+		}))
+
+		block, err := handler.GetBlockWithTxHashes(l1AcceptedId)
+		require.Nil(t, err)
+		assert.Equal(t, block1.Hash, block.Hash)
+		assert.Equal(t, rpc.BlockStatusAcceptedL1, block.Status)
+		update, err := handler.GetStateUpdate(l1AcceptedId)
+		require.Nil(t, err)
+		assert.Equal(t, block1.Hash, update.BlockHash)
+
+		for number, status := range map[uint64]rpc.BlockStatus{
+			0: rpc.BlockStatusAcceptedL1,
+			1: rpc.BlockStatusAcceptedL1,
+			2: rpc.BlockStatusAcceptedL2,
+		} {
+			block, err := handler.GetBlockWithTxs(&rpc.BlockId{Number: number})
+			require.Nil(t, err)
+			assert.Equal(t, status, block.Status, "block %d", number)
+		}
+
+		receipt, err := handler.GetTransactionReceiptByHash(block1.Transactions[0].Hash())
+		require.Nil(t, err)
+		assert.Equal(t, rpc.TxnStatusAcceptedL1, receipt.Common().Status)
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		pendingId := &rpc.BlockId{Pending: true}
+		gwPending, gwErr := gw.BlockPending(ctx)
+		require.NoError(t, gwErr)
+		latestBlock, err := handler.GetBlockWithTxHashes(&rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+
+		block, err := handler.GetBlockWithTxHashes(pendingId)
+		require.Nil(t, err)
+		assert.Equal(t, rpc.BlockStatusPending, block.Status)
+		assert.Equal(t, latestBlock.Hash, block.ParentHash)
+		assert.Nil(t, block.Hash)
+		assert.Nil(t, block.Number)
+		assert.Equal(t, len(gwPending.Transactions), len(block.TxnHashes))
+
+		blockWithTxs, err := handler.GetBlockWithTxs(pendingId)
+		require.Nil(t, err)
+		assert.Equal(t, rpc.BlockStatusPending, blockWithTxs.Status)
+		assert.Equal(t, len(gwPending.Transactions), len(blockWithTxs.Transactions))
+
+		pendingTxnHash := gwPending.Transactions[0].Hash()
+		txn, err := handler.GetTransactionByHash(pendingTxnHash)
+		require.Nil(t, err)
+		assert.Equal(t, pendingTxnHash, txn.Hash)
+
+		receipt, err := handler.GetTransactionReceiptByHash(pendingTxnHash)
+		require.Nil(t, err)
+		assert.Equal(t, rpc.TxnStatusPending, receipt.Common().Status)
+		assert.Nil(t, receipt.Common().BlockHash)
+		assert.Nil(t, receipt.Common().BlockNumber)
+
+		update, err := handler.GetStateUpdate(pendingId)
+		require.Nil(t, err)
+		assert.Nil(t, update.BlockHash)
+		assert.Equal(t, latestBlock.NewRoot, update.OldRoot)
+
+		// the pending block updates a contract deployed in block 0 and deploys a new one
+		addr, _ := new(felt.Felt).SetString("0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6")
+		newAddr, _ := new(felt.Felt).SetString("0x2b7b2b6fb86f7cbdca4b6e5fd3e1f0de0cb9c4b6dd15ab51c0e6a1a0ad0ae0b")
+		newClassHash, _ := new(felt.Felt).SetString("0x1efa8f84fd4dff9e2902ec88717cf0dafc8c188f80c3450615944a469428f7f")
+
+		value, err := handler.GetStorageAt(addr, new(felt.Felt).SetUint64(5), pendingId)
+		require.Nil(t, err)
+		assert.Equal(t, new(felt.Felt).SetUint64(0x7e6), value)
+		value, err = handler.GetStorageAt(addr, new(felt.Felt).SetUint64(5), &rpc.BlockId{Latest: true})
+		require.Nil(t, err)
+		assert.Equal(t, new(felt.Felt).SetUint64(0x22b), value)
+		value, err = handler.GetStorageAt(newAddr, new(felt.Felt).SetUint64(5), pendingId)
+		require.Nil(t, err)
+		assert.Equal(t, new(felt.Felt).SetUint64(0x42), value)
+		value, err = handler.GetStorageAt(newAddr, new(felt.Felt).SetUint64(6), pendingId)
+		require.Nil(t, err)
+		assert.Equal(t, &felt.Zero, value)
+		_, err = handler.GetStorageAt(newAddr, new(felt.Felt).SetUint64(5), &rpc.BlockId{Latest: true})
+		assert.Equal(t, rpc.ErrContractNotFound, err)
+
+		nonce, err := handler.GetNonce(pendingId, addr)
+		require.Nil(t, err)
+		assert.Equal(t, new(felt.Felt).SetUint64(1), nonce)
+		nonce, err = handler.GetNonce(pendingId, newAddr)
+		require.Nil(t, err)
+		assert.Equal(t, &felt.Zero, nonce)
+
+		classHash, err := handler.GetClassHashAt(pendingId, newAddr)
+		require.Nil(t, err)
+		assert.Equal(t, newClassHash, classHash)
+		classHash, err = handler.GetClassHashAt(pendingId, addr)
+		require.Nil(t, err)
+		assert.NotEqual(t, newClassHash, classHash)
+
+		class, err := handler.GetClassAt(pendingId, newAddr)
+		require.Nil(t, err)
+		assert.NotEmpty(t, class.Program)
+		_, err = handler.GetClass(&rpc.BlockId{Latest: true}, newClassHash)
+		assert.Equal(t, rpc.ErrClassHashNotFound, err)
+
+		pendingEvents := 0
+		for _, receipt := range gwPending.Receipts {
+			pendingEvents += len(receipt.Events)
+		}
+		chunk, err := handler.GetEvents(&rpc.EventsArg{
+			EventFilter:       rpc.EventFilter{FromBlock: &rpc.BlockId{Number: 0}, ToBlock: pendingId},
+			ResultPageRequest: rpc.ResultPageRequest{ChunkSize: 1024},
+		})
+		require.Nil(t, err)
+		require.Equal(t, pendingEvents, len(chunk.Events))
+		assert.Nil(t, chunk.Events[0].BlockHash)
+		assert.Nil(t, chunk.Events[0].BlockNumber)
+
+		// the v0.3 filter matches the keys of an event by their position
+		firstKey := chunk.Events[0].Keys[0]
+		withFirstKey := 0
+		for _, receipt := range gwPending.Receipts {
+			for _, event := range receipt.Events {
+				if len(event.Keys) > 0 && event.Keys[0].Equal(firstKey) {
+					withFirstKey++
+				}
+			}
+		}
+		var argsV0_3 rpc.EventsArgV0_3
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"from_block": {"block_number": 0},
+			"to_block": "pending",
+			"keys": [["`+firstKey.String()+`"], []],
+			"chunk_size": 1024
+		}`), &argsV0_3))
+		chunk, err = handler.GetEventsV0_3(&argsV0_3)
+		require.Nil(t, err)
+		assert.Equal(t, withFirstKey, len(chunk.Events))
+
+		argsV0_3.Keys = [][]*felt.Felt{{}, {firstKey}}
+		chunk, err = handler.GetEventsV0_3(&argsV0_3)
+		require.Nil(t, err)
+		for _, event := range chunk.Events {
+			assert.Equal(t, firstKey, event.Keys[1])
+		}
+	})
+
 	canceler()
 	<-syncNodeChan
 }
@@ -126,11 +571,32 @@ func (r *fakeBcReader) GetTransactionByHash(hash *felt.Felt) (transaction core.T
 	return r.gw.Transaction(context.Background(), hash)
 }
 
+type fakeSyncReader struct {
+	startingBlockNumber uint64
+	highestBlockHeader  *core.Header
+}
+
+func (r *fakeSyncReader) StartingBlockNumber() (uint64, bool) {
+	return r.startingBlockNumber, true
+}
+
+func (r *fakeSyncReader) HighestBlockHeader() *core.Header {
+	return r.highestBlockHeader
+}
+
+func (r *fakeSyncReader) SubscribeNewHeads() *sync.Subscription[*core.Header] {
+	return nil
+}
+
+func (r *fakeSyncReader) SubscribePending() *sync.Subscription[*blockchain.Pending] {
+	return nil
+}
+
 func TestGetTransactionByHash(t *testing.T) {
 	mainnetGw, closer := testsource.NewTestGateway(utils.MAINNET)
 	defer closer()
 
-	handler := rpc.New(&fakeBcReader{nil, mainnetGw}, nil)
+	handler := rpc.New(&fakeBcReader{nil, mainnetGw}, nil, nil)
 
 	tests := map[string]struct {
 		hash     string
@@ -299,3 +765,19 @@ func TestGetTransactionByHash(t *testing.T) {
 		})
 	}
 }
+
+func TestMethods(t *testing.T) {
+	handler := rpc.New(nil, nil, nil)
+	names := func(methods []jsonrpc.Method) []string {
+		var names []string
+		for _, method := range methods {
+			names = append(names, method.Name)
+		}
+		return names
+	}
+
+	// every version serves the complete API
+	v0_2, v0_3 := names(handler.MethodsV0_2()), names(handler.MethodsV0_3())
+	assert.Len(t, v0_2, 15)
+	assert.ElementsMatch(t, v0_2, v0_3)
+}
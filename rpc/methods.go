@@ -0,0 +1,46 @@
+package rpc
+
+import "github.com/NethermindEth/juno/jsonrpc"
+
+const (
+	// SpecVersionV0_2 is the version of the starknet-specs that MethodsV0_2 implement
+	SpecVersionV0_2 = "0.2.1"
+	// SpecVersionV0_3 is the version of the starknet-specs that MethodsV0_3 implement
+	SpecVersionV0_3 = "0.3.0"
+)
+
+// MethodsV0_2 returns the JSON-RPC methods of the v0.2 API
+func (h *Handler) MethodsV0_2() []jsonrpc.Method {
+	return append(h.sharedMethods(), []jsonrpc.Method{
+		{"starknet_getStateUpdate", []jsonrpc.Parameter{{Name: "block_id"}}, h.GetStateUpdate},
+		{"starknet_getEvents", []jsonrpc.Parameter{{Name: "filter"}}, h.GetEvents},
+	}...)
+}
+
+// MethodsV0_3 returns the JSON-RPC methods of the v0.3 API
+func (h *Handler) MethodsV0_3() []jsonrpc.Method {
+	return append(h.sharedMethods(), []jsonrpc.Method{
+		{"starknet_getStateUpdate", []jsonrpc.Parameter{{Name: "block_id"}}, h.GetStateUpdateV0_3},
+		{"starknet_getEvents", []jsonrpc.Parameter{{Name: "filter"}}, h.GetEventsV0_3},
+	}...)
+}
+
+// sharedMethods returns the JSON-RPC methods that every version of the API serves with the
+// same handler, since their parameters and results did not change between the versions
+func (h *Handler) sharedMethods() []jsonrpc.Method {
+	return []jsonrpc.Method{
+		{"starknet_chainId", nil, h.ChainId},
+		{"starknet_blockNumber", nil, h.BlockNumber},
+		{"starknet_blockHashAndNumber", nil, h.BlockNumberAndHash},
+		{"starknet_getBlockWithTxHashes", []jsonrpc.Parameter{{Name: "block_id"}}, h.GetBlockWithTxHashes},
+		{"starknet_getBlockWithTxs", []jsonrpc.Parameter{{Name: "block_id"}}, h.GetBlockWithTxs},
+		{"starknet_getTransactionByHash", []jsonrpc.Parameter{{Name: "transaction_hash"}}, h.GetTransactionByHash},
+		{"starknet_getTransactionReceipt", []jsonrpc.Parameter{{Name: "transaction_hash"}}, h.GetTransactionReceiptByHash},
+		{"starknet_getNonce", []jsonrpc.Parameter{{Name: "block_id"}, {Name: "contract_address"}}, h.GetNonce},
+		{"starknet_getClass", []jsonrpc.Parameter{{Name: "block_id"}, {Name: "class_hash"}}, h.GetClass},
+		{"starknet_getClassAt", []jsonrpc.Parameter{{Name: "block_id"}, {Name: "contract_address"}}, h.GetClassAt},
+		{"starknet_getClassHashAt", []jsonrpc.Parameter{{Name: "block_id"}, {Name: "contract_address"}}, h.GetClassHashAt},
+		{"starknet_getStorageAt", []jsonrpc.Parameter{{Name: "contract_address"}, {Name: "key"}, {Name: "block_id"}}, h.GetStorageAt},
+		{"starknet_syncing", nil, h.Syncing},
+	}
+}
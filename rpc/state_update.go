@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L909
+type StateUpdate struct {
+	BlockHash *felt.Felt `json:"block_hash,omitempty"`
+	NewRoot   *felt.Felt `json:"new_root,omitempty"`
+	OldRoot   *felt.Felt `json:"old_root"`
+	StateDiff *StateDiff `json:"state_diff"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L929
+type StateDiff struct {
+	StorageDiffs      []StorageDiff      `json:"storage_diffs"`
+	DeclaredContracts []*felt.Felt       `json:"declared_contract_hashes"`
+	DeployedContracts []DeployedContract `json:"deployed_contracts"`
+	Nonces            []Nonce            `json:"nonces"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L970
+type Nonce struct {
+	ContractAddress *felt.Felt `json:"contract_address"`
+	Nonce           *felt.Felt `json:"nonce"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1004
+type StorageDiff struct {
+	Address        *felt.Felt `json:"address"`
+	StorageEntries []Entry    `json:"storage_entries"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1016
+type Entry struct {
+	Key   *felt.Felt `json:"key"`
+	Value *felt.Felt `json:"value"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1047
+type DeployedContract struct {
+	Address   *felt.Felt `json:"address"`
+	ClassHash *felt.Felt `json:"class_hash"`
+}
+
+// StateUpdateV0_3 is the state update of the v0.3 API, it differs from [StateUpdate] in the
+// layout of the state diff only.
+//
+// https://github.com/starkware-libs/starknet-specs/blob/v0.3.0/api/starknet_api_openrpc.json
+type StateUpdateV0_3 struct {
+	BlockHash *felt.Felt     `json:"block_hash,omitempty"`
+	NewRoot   *felt.Felt     `json:"new_root,omitempty"`
+	OldRoot   *felt.Felt     `json:"old_root"`
+	StateDiff *StateDiffV0_3 `json:"state_diff"`
+}
+
+// StateDiffV0_3 tells Cairo 0 classes, which are declared by hash alone, apart from the
+// classes that are declared with a compiled class hash.
+type StateDiffV0_3 struct {
+	StorageDiffs              []StorageDiff      `json:"storage_diffs"`
+	DeprecatedDeclaredClasses []*felt.Felt       `json:"deprecated_declared_classes"`
+	DeclaredClasses           []DeclaredClass    `json:"declared_classes"`
+	DeployedContracts         []DeployedContract `json:"deployed_contracts"`
+	ReplacedClasses           []ReplacedClass    `json:"replaced_classes"`
+	Nonces                    []Nonce            `json:"nonces"`
+}
+
+type DeclaredClass struct {
+	ClassHash         *felt.Felt `json:"class_hash"`
+	CompiledClassHash *felt.Felt `json:"compiled_class_hash"`
+}
+
+type ReplacedClass struct {
+	ContractAddress *felt.Felt `json:"contract_address"`
+	ClassHash       *felt.Felt `json:"class_hash"`
+}
@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/jsonrpc"
+)
+
+// SubscriptionEventFilter selects the events that are sent to an events subscriber
+type SubscriptionEventFilter struct {
+	Address *felt.Felt   `json:"address"`
+	Keys    []*felt.Felt `json:"keys"`
+}
+
+// TransactionStatus is sent to a transaction status subscriber whenever the status of the
+// transaction changes
+type TransactionStatus struct {
+	Hash   *felt.Felt `json:"transaction_hash"`
+	Status TxnStatus  `json:"status"`
+}
+
+// SubscribeNewHeads sends the header of every block stored from now on to the subscriber
+func (h *Handler) SubscribeNewHeads(sub *jsonrpc.Subscription) *jsonrpc.Error {
+	heads := h.syncReader.SubscribeNewHeads()
+	go func() {
+		defer heads.Unsubscribe()
+		for {
+			select {
+			case <-sub.Done():
+				return
+			case header := <-heads.C:
+				if err := sub.Notify(adaptBlockHeader(header)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// SubscribeEvents sends the events matching filter of every block stored from now on to
+// the subscriber, one at a time
+func (h *Handler) SubscribeEvents(sub *jsonrpc.Subscription, filter SubscriptionEventFilter) *jsonrpc.Error {
+	heads := h.syncReader.SubscribeNewHeads()
+	go func() {
+		defer heads.Unsubscribe()
+		for {
+			select {
+			case <-sub.Done():
+				return
+			case header := <-heads.C:
+				if err := h.notifyEvents(sub, &filter, header); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (h *Handler) notifyEvents(sub *jsonrpc.Subscription, filter *SubscriptionEventFilter, header *core.Header) error {
+	blockFilter := &blockchain.EventFilter{
+		FromBlock: header.Number,
+		ToBlock:   header.Number,
+		Address:   filter.Address,
+		Keys:      filter.Keys,
+	}
+	for start := (&blockchain.EventPosition{BlockNumber: header.Number}); start != nil; {
+		events, next, err := h.bcReader.Events(blockFilter, *start, maxEventChunkSize, 0)
+		if err != nil {
+			// the block may have been reverted in the meantime
+			return nil
+		}
+		for _, event := range events {
+			if err = sub.Notify(adaptFilteredEvent(event)); err != nil {
+				return err
+			}
+		}
+		start = next
+	}
+	return nil
+}
+
+// SubscribeTransactionStatus sends the status of the transaction with the given hash to the
+// subscriber every time it changes, starting with its current status if it is known
+func (h *Handler) SubscribeTransactionStatus(sub *jsonrpc.Subscription, hash *felt.Felt) *jsonrpc.Error {
+	if hash == nil {
+		return &jsonrpc.Error{Code: jsonrpc.InvalidParams, Message: "transaction hash is required"}
+	}
+
+	heads := h.syncReader.SubscribeNewHeads()
+	pending := h.syncReader.SubscribePending()
+	go func() {
+		defer heads.Unsubscribe()
+		defer pending.Unsubscribe()
+
+		var lastStatus *TxnStatus
+		for {
+			if status, found := h.transactionStatus(hash); found && (lastStatus == nil || *lastStatus != status) {
+				if err := sub.Notify(&TransactionStatus{Hash: hash, Status: status}); err != nil {
+					return
+				}
+				lastStatus = &status
+			}
+
+			select {
+			case <-sub.Done():
+				return
+			case <-heads.C:
+			case <-pending.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// transactionStatus returns the status of the transaction with the given hash. If it is
+// neither in the chain nor in the pending block, false is returned.
+func (h *Handler) transactionStatus(hash *felt.Felt) (TxnStatus, bool) {
+	if _, _, number, err := h.bcReader.GetReceipt(hash); err == nil {
+		if h.isAcceptedOnL1(number) {
+			return TxnStatusAcceptedL1, true
+		}
+		return TxnStatusAcceptedL2, true
+	}
+
+	if pending, err := h.bcReader.Pending(); err == nil {
+		if _, _, found := pending.Transaction(hash); found {
+			return TxnStatusPending, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,136 @@
+package rpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/rpc"
+	"github.com/NethermindEth/juno/sync"
+	"github.com/NethermindEth/juno/testsource"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingConn struct {
+	msgs chan []byte
+	done chan struct{}
+}
+
+func (c *recordingConn) Write(msg []byte) error {
+	c.msgs <- msg
+	return nil
+}
+
+func (c *recordingConn) Done() <-chan struct{} {
+	return c.done
+}
+
+func TestSubscriptions(t *testing.T) {
+	bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	log := utils.NewNopZapLogger()
+	gw, closer := testsource.NewTestGateway(utils.MAINNET)
+	defer closer()
+	synchronizer := sync.NewSynchronizer(bc, testsource.StubClasses(gw), log).WithPendingPollInterval(100 * time.Millisecond)
+	handler := rpc.New(bc, synchronizer, utils.MAINNET.ChainId())
+
+	server := jsonrpc.NewServer()
+	require.NoError(t, server.RegisterSubscriptions(jsonrpc.SubscriptionMethods{
+		Subscribe:    "starknet_subscribe",
+		Unsubscribe:  "starknet_unsubscribe",
+		Notification: "starknet_subscription",
+	}, []jsonrpc.Topic{
+		{Name: "newHeads", Handler: handler.SubscribeNewHeads},
+		{Name: "events", Handler: handler.SubscribeEvents},
+		{Name: "transactionStatus", Handler: handler.SubscribeTransactionStatus},
+	}))
+
+	block1, err := gw.BlockByNumber(context.Background(), 1)
+	require.NoError(t, err)
+	txHash := block1.Transactions[0].Hash()
+
+	conn := &recordingConn{msgs: make(chan []byte, 10_000), done: make(chan struct{})}
+	defer close(conn.done)
+	for id, req := range []string{
+		`{"jsonrpc":"2.0","method":"starknet_subscribe","params":["newHeads"],"id":1}`,
+		`{"jsonrpc":"2.0","method":"starknet_subscribe","params":["events",{}],"id":2}`,
+		`{"jsonrpc":"2.0","method":"starknet_subscribe","params":["transactionStatus","` + txHash.String() + `"],"id":3}`,
+	} {
+		require.NoError(t, server.HandleConn(context.Background(), conn, []byte(req)))
+		assert.JSONEq(t, fmt.Sprintf(`{"jsonrpc":"2.0","result":%d,"id":%d}`, id+1, id+1), string(<-conn.msgs))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Second)
+		cancel()
+	}()
+	require.NoError(t, synchronizer.Run(ctx))
+
+	head, err := bc.Head()
+	require.NoError(t, err)
+	allEvents, _, err := bc.Events(&blockchain.EventFilter{ToBlock: head.Number}, blockchain.EventPosition{}, 1024, 0)
+	require.NoError(t, err)
+
+	type notification struct {
+		Method string `json:"method"`
+		Params struct {
+			Subscription uint64          `json:"subscription"`
+			Result       json.RawMessage `json:"result"`
+		} `json:"params"`
+	}
+	var heads []uint64
+	var events int
+	var statuses []string
+	require.Eventually(t, func() bool {
+		for {
+			select {
+			case msg := <-conn.msgs:
+				var n notification
+				require.NoError(t, json.Unmarshal(msg, &n))
+				require.Equal(t, "starknet_subscription", n.Method)
+				switch n.Params.Subscription {
+				case 1:
+					var header rpc.BlockHeader
+					require.NoError(t, json.Unmarshal(n.Params.Result, &header))
+					heads = append(heads, *header.Number)
+				case 2:
+					events++
+				case 3:
+					var status struct {
+						Hash   string `json:"transaction_hash"`
+						Status string `json:"status"`
+					}
+					require.NoError(t, json.Unmarshal(n.Params.Result, &status))
+					assert.Equal(t, txHash.String(), status.Hash)
+					statuses = append(statuses, status.Status)
+				}
+			default:
+				return len(heads) == int(head.Number)+1 && events == len(allEvents) && len(statuses) == 1
+			}
+		}
+	}, time.Second, 10*time.Millisecond)
+
+	for i, number := range heads {
+		assert.Equal(t, uint64(i), number)
+	}
+	assert.Equal(t, []string{"ACCEPTED_ON_L2"}, statuses)
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		require.NoError(t, server.HandleConn(context.Background(), conn, []byte(`{"jsonrpc":"2.0","method":"starknet_unsubscribe","params":[1],"id":4}`)))
+		assert.JSONEq(t, `{"jsonrpc":"2.0","result":true,"id":4}`, string(<-conn.msgs))
+		require.NoError(t, server.HandleConn(context.Background(), conn, []byte(`{"jsonrpc":"2.0","method":"starknet_unsubscribe","params":[1],"id":5}`)))
+		assert.JSONEq(t, `{"jsonrpc":"2.0","result":false,"id":5}`, string(<-conn.msgs))
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		require.NoError(t, server.HandleConn(context.Background(), conn, []byte(`{"jsonrpc":"2.0","method":"starknet_subscribe","params":["blocks"],"id":6}`)))
+		assert.Contains(t, string(<-conn.msgs), `"code":-32602`)
+	})
+}
@@ -0,0 +1,36 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// NumAsHex is a number that is encoded as a 0x-prefixed hex string
+type NumAsHex uint64
+
+func (n NumAsHex) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"0x%x\"", uint64(n))), nil
+}
+
+// Sync is the result of starknet_syncing. When the node is not syncing it is encoded as false.
+//
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+type Sync struct {
+	Syncing             bool       `json:"-"`
+	StartingBlockHash   *felt.Felt `json:"starting_block_hash,omitempty"`
+	StartingBlockNumber NumAsHex   `json:"starting_block_num"`
+	CurrentBlockHash    *felt.Felt `json:"current_block_hash,omitempty"`
+	CurrentBlockNumber  NumAsHex   `json:"current_block_num"`
+	HighestBlockHash    *felt.Felt `json:"highest_block_hash"`
+	HighestBlockNumber  NumAsHex   `json:"highest_block_num"`
+}
+
+func (s *Sync) MarshalJSON() ([]byte, error) {
+	if !s.Syncing {
+		return []byte("false"), nil
+	}
+	type syncStatus Sync // avoids calling MarshalJSON recursively
+	return json.Marshal((*syncStatus)(s))
+}
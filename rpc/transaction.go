@@ -1,6 +1,8 @@
 package rpc
 
 import (
+	"errors"
+
 	"github.com/NethermindEth/juno/core/felt"
 )
 
@@ -21,3 +23,89 @@ type Transaction struct {
 	EntryPointSelector  *felt.Felt    `json:"entry_point_selector,omitempty"`
 	CompiledClassHash   *felt.Felt    `json:"compiled_class_hash,omitempty"`
 }
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1854-L1863
+type TxnStatus uint8
+
+const (
+	TxnStatusPending TxnStatus = iota
+	TxnStatusAcceptedL2
+	TxnStatusAcceptedL1
+	TxnStatusRejected
+)
+
+func (s TxnStatus) MarshalJSON() ([]byte, error) {
+	switch s {
+	case TxnStatusPending:
+		return []byte("\"PENDING\""), nil
+	case TxnStatusAcceptedL2:
+		return []byte("\"ACCEPTED_ON_L2\""), nil
+	case TxnStatusAcceptedL1:
+		return []byte("\"ACCEPTED_ON_L1\""), nil
+	case TxnStatusRejected:
+		return []byte("\"REJECTED\""), nil
+	default:
+		return nil, errors.New("unknown transaction status")
+	}
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1736
+type MsgToL1 struct {
+	To      *felt.Felt   `json:"to_address"`
+	Payload []*felt.Felt `json:"payload"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1026
+type Event struct {
+	From *felt.Felt   `json:"from_address"`
+	Keys []*felt.Felt `json:"keys"`
+	Data []*felt.Felt `json:"data"`
+}
+
+// TransactionReceipt is the receipt of a transaction of any type, one of the receipt types below
+//
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1779
+type TransactionReceipt interface {
+	Common() *CommonReceiptProperties
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1791
+type CommonReceiptProperties struct {
+	Type         string     `json:"type"`
+	Hash         *felt.Felt `json:"transaction_hash"`
+	ActualFee    *felt.Felt `json:"actual_fee"`
+	Status       TxnStatus  `json:"status"`
+	BlockHash    *felt.Felt `json:"block_hash,omitempty"`
+	BlockNumber  *uint64    `json:"block_number,omitempty"`
+	MessagesSent []*MsgToL1 `json:"messages_sent"`
+	Events       []*Event   `json:"events"`
+}
+
+// Common returns the properties shared by the receipts of every transaction type
+func (r *CommonReceiptProperties) Common() *CommonReceiptProperties {
+	return r
+}
+
+type InvokeTransactionReceipt struct {
+	CommonReceiptProperties
+}
+
+type DeclareTransactionReceipt struct {
+	CommonReceiptProperties
+}
+
+type DeployTransactionReceipt struct {
+	CommonReceiptProperties
+	ContractAddress *felt.Felt `json:"contract_address"`
+}
+
+type DeployAccountTransactionReceipt struct {
+	CommonReceiptProperties
+	ContractAddress *felt.Felt `json:"contract_address"`
+}
+
+type L1HandlerTransactionReceipt struct {
+	CommonReceiptProperties
+	// MessageHash identifies the L1 to L2 message the transaction consumed
+	MessageHash string `json:"message_hash"`
+}
@@ -0,0 +1,253 @@
+package archive
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/NethermindEth/juno/clients"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/starknetdata/gateway"
+)
+
+const (
+	blockDir       = "block"
+	stateUpdateDir = "state_update"
+	transactionDir = "transaction"
+	classDir       = "class"
+
+	latest  = "latest"
+	pending = "pending"
+
+	jsonExt = ".json"
+	gzipExt = ".json.gz"
+)
+
+// Archive reads blocks, state updates, transactions and classes from files holding the
+// responses of the feeder gateway, laid out as follows:
+//
+//	block/<number>.json, block/latest.json, block/pending.json
+//	state_update/<number>.json, state_update/pending.json
+//	transaction/<hash>.json
+//	class/<hash>.json
+//
+// Hashes are written in lower case hex with a 0x prefix and without leading zeros. Files can
+// be gzip compressed, in which case their extension is .json.gz. The files are either in a
+// directory or in a zip archive, optionally wrapped in a single top-level directory.
+type Archive struct {
+	files  fs.FS
+	closer io.Closer
+	// name of the block file BlockLatest reads, determined once when the archive is opened
+	latest string
+}
+
+// New opens the directory or zip archive at the given path
+func New(archivePath string) (*Archive, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	a := new(Archive)
+	if info.IsDir() {
+		a.files = os.DirFS(archivePath)
+	} else {
+		zipReader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("open archive %s: %w", archivePath, err)
+		}
+		a.files, a.closer = zipReader, zipReader
+	}
+
+	if a.files, err = root(a.files); err != nil {
+		a.Close()
+		return nil, fmt.Errorf("open archive %s: %w", archivePath, err)
+	}
+
+	a.latest = latest
+	if !a.exists(blockDir, latest) {
+		// listing the block directory is expensive for large archives, so it is done only once
+		number, err := a.highestBlockNumber()
+		if err != nil {
+			a.Close()
+			return nil, fmt.Errorf("open archive %s: %w", archivePath, err)
+		}
+		a.latest = strconv.FormatUint(number, 10)
+	}
+	return a, nil
+}
+
+// root returns the directory that holds the block directory, which is either files itself or
+// its only subdirectory
+func root(files fs.FS) (fs.FS, error) {
+	if _, err := fs.Stat(files, blockDir); err == nil {
+		return files, nil
+	}
+
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		if _, err := fs.Stat(files, path.Join(entries[0].Name(), blockDir)); err == nil {
+			return fs.Sub(files, entries[0].Name())
+		}
+	}
+	return nil, errors.New("no block directory found")
+}
+
+// Close releases the archive file, if any
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// BlockByNumber reads the block with the given number
+func (a *Archive) BlockByNumber(ctx context.Context, blockNumber uint64) (*core.Block, error) {
+	return a.block(strconv.FormatUint(blockNumber, 10))
+}
+
+// BlockLatest reads block/latest.json, or the block with the highest number when the archive
+// was opened if there is no such file
+func (a *Archive) BlockLatest(ctx context.Context) (*core.Block, error) {
+	return a.block(a.latest)
+}
+
+// BlockPending reads block/pending.json
+func (a *Archive) BlockPending(ctx context.Context) (*core.Block, error) {
+	return a.block(pending)
+}
+
+func (a *Archive) block(name string) (*core.Block, error) {
+	response := new(clients.Block)
+	if err := a.read(blockDir, name, response); err != nil {
+		return nil, err
+	}
+	return gateway.AdaptBlock(response)
+}
+
+func (a *Archive) highestBlockNumber() (uint64, error) {
+	entries, err := fs.ReadDir(a.files, blockDir)
+	if err != nil {
+		return 0, err
+	}
+
+	var highest uint64
+	found := false
+	for _, entry := range entries {
+		name := strings.TrimSuffix(strings.TrimSuffix(entry.Name(), gzipExt), jsonExt)
+		if number, err := strconv.ParseUint(name, 10, 64); err == nil && (!found || number > highest) {
+			highest, found = number, true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no block found: %w", fs.ErrNotExist)
+	}
+	return highest, nil
+}
+
+// StateUpdate reads the state update of the block with the given number
+func (a *Archive) StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error) {
+	return a.stateUpdate(strconv.FormatUint(blockNumber, 10))
+}
+
+// StateUpdatePending reads state_update/pending.json
+func (a *Archive) StateUpdatePending(ctx context.Context) (*core.StateUpdate, error) {
+	return a.stateUpdate(pending)
+}
+
+// StateUpdatePendingWithBlock reads state_update/pending.json and block/pending.json
+func (a *Archive) StateUpdatePendingWithBlock(ctx context.Context) (*core.StateUpdate, *core.Block, error) {
+	block, err := a.block(pending)
+	if err != nil {
+		return nil, nil, err
+	}
+	stateUpdate, err := a.stateUpdate(pending)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stateUpdate, block, nil
+}
+
+func (a *Archive) stateUpdate(name string) (*core.StateUpdate, error) {
+	response := new(clients.StateUpdate)
+	if err := a.read(stateUpdateDir, name, response); err != nil {
+		return nil, err
+	}
+	return gateway.AdaptStateUpdate(response)
+}
+
+// Transaction reads the transaction with the given hash
+func (a *Archive) Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error) {
+	response := new(clients.TransactionStatus)
+	if err := a.read(transactionDir, hashName(transactionHash), response); err != nil {
+		return nil, err
+	}
+	return gateway.AdaptTransaction(response.Transaction)
+}
+
+// Class reads the class with the given hash
+func (a *Archive) Class(ctx context.Context, classHash *felt.Felt) (*core.Class, error) {
+	response := new(clients.ClassDefinition)
+	if err := a.read(classDir, hashName(classHash), response); err != nil {
+		return nil, err
+	}
+	return gateway.AdaptClass(response)
+}
+
+func hashName(hash *felt.Felt) string {
+	return "0x" + hash.Text(16)
+}
+
+// exists reports whether there is a file with the given name in dir
+func (a *Archive) exists(dir, name string) bool {
+	for _, ext := range []string{jsonExt, gzipExt} {
+		if _, err := fs.Stat(a.files, path.Join(dir, name+ext)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// read decodes the file with the given name in dir into v, the error wraps [fs.ErrNotExist] if
+// there is no such file
+func (a *Archive) read(dir, name string, v any) error {
+	compressed := false
+	file, err := a.files.Open(path.Join(dir, name+jsonExt))
+	if errors.Is(err, fs.ErrNotExist) {
+		compressed = true
+		file, err = a.files.Open(path.Join(dir, name+gzipExt))
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s %s: %w", dir, name, fs.ErrNotExist)
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if compressed {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	if err = json.NewDecoder(reader).Decode(v); err != nil {
+		return fmt.Errorf("%s %s: %w", dir, name, err)
+	}
+	return nil
+}
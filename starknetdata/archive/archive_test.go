@@ -0,0 +1,147 @@
+package archive_test
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/starknetdata/archive"
+	"github.com/NethermindEth/juno/testsource"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testData = "../../testsource/testdata/mainnet"
+
+func TestArchive(t *testing.T) {
+	gw, closeFn := testsource.NewTestGateway(utils.MAINNET)
+	defer closeFn()
+	ctx := context.Background()
+
+	a, err := archive.New(testData)
+	require.NoError(t, err)
+	defer a.Close()
+
+	t.Run("blocks and state updates", func(t *testing.T) {
+		for _, number := range []uint64{0, 1, 2, 147, 11817} {
+			want, err := gw.BlockByNumber(ctx, number)
+			require.NoError(t, err)
+			got, err := a.BlockByNumber(ctx, number)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+		for _, number := range []uint64{0, 1, 2} {
+			want, err := gw.StateUpdate(ctx, number)
+			require.NoError(t, err)
+			got, err := a.StateUpdate(ctx, number)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+	})
+	t.Run("latest and pending", func(t *testing.T) {
+		want, err := gw.BlockLatest(ctx)
+		require.NoError(t, err)
+		got, err := a.BlockLatest(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		want, err = gw.BlockPending(ctx)
+		require.NoError(t, err)
+		got, err = a.BlockPending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		wantUpdate, err := gw.StateUpdatePending(ctx)
+		require.NoError(t, err)
+		gotUpdate, err := a.StateUpdatePending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, wantUpdate, gotUpdate)
+
+		gotUpdate, got, err = a.StateUpdatePendingWithBlock(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, wantUpdate, gotUpdate)
+		assert.Equal(t, want, got)
+	})
+	t.Run("transactions and classes", func(t *testing.T) {
+		txnHash, err := new(felt.Felt).SetString("0x1b4d9f09276629d496af1af8ff00173c11ff146affacb1b5c858d7aa89001ae")
+		require.NoError(t, err)
+		wantTxn, err := gw.Transaction(ctx, txnHash)
+		require.NoError(t, err)
+		gotTxn, err := a.Transaction(ctx, txnHash)
+		require.NoError(t, err)
+		assert.Equal(t, wantTxn, gotTxn)
+
+		classHash, err := new(felt.Felt).SetString("0x1efa8f84fd4dff9e2902ec88717cf0dafc8c188f80c3450615944a469428f7f")
+		require.NoError(t, err)
+		wantClass, err := gw.Class(ctx, classHash)
+		require.NoError(t, err)
+		gotClass, err := a.Class(ctx, classHash)
+		require.NoError(t, err)
+		assert.Equal(t, wantClass, gotClass)
+	})
+	t.Run("missing files", func(t *testing.T) {
+		_, err := a.BlockByNumber(ctx, 3)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		_, err = a.Class(ctx, new(felt.Felt).SetUint64(1))
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+	t.Run("not an archive", func(t *testing.T) {
+		_, err := archive.New(t.TempDir())
+		assert.Error(t, err)
+		_, err = archive.New(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+
+		noBlocks := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(noBlocks, "block"), 0o755))
+		_, err = archive.New(noBlocks)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
+
+func TestZipArchive(t *testing.T) {
+	ctx := context.Background()
+	dir, err := archive.New(testData)
+	require.NoError(t, err)
+	defer dir.Close()
+
+	// a zip archive wrapped in a directory, holding gzip compressed blocks and no latest block
+	zipPath := filepath.Join(t.TempDir(), "mainnet.zip")
+	zipFile, err := os.Create(zipPath)
+	require.NoError(t, err)
+	zipWriter := zip.NewWriter(zipFile)
+	for _, number := range []string{"0", "1"} {
+		blockJSON, err := os.ReadFile(filepath.Join(testData, "block", number+".json"))
+		require.NoError(t, err)
+		entry, err := zipWriter.Create("mainnet/block/" + number + ".json.gz")
+		require.NoError(t, err)
+		gzipWriter := gzip.NewWriter(entry)
+		_, err = gzipWriter.Write(blockJSON)
+		require.NoError(t, err)
+		require.NoError(t, gzipWriter.Close())
+	}
+	require.NoError(t, zipWriter.Close())
+	require.NoError(t, zipFile.Close())
+
+	a, err := archive.New(zipPath)
+	require.NoError(t, err)
+	defer a.Close()
+
+	want, err := dir.BlockByNumber(ctx, 1)
+	require.NoError(t, err)
+	got, err := a.BlockByNumber(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	latest, err := a.BlockLatest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, want, latest)
+
+	_, err = a.StateUpdate(ctx, 0)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
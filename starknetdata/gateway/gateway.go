@@ -1,7 +1,11 @@
 package gateway
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 
 	"github.com/NethermindEth/juno/clients"
@@ -38,6 +42,27 @@ func (g *Gateway) BlockByNumber(ctx context.Context, blockNumber uint64) (*core.
 	return AdaptBlock(response)
 }
 
+// BlockPending gets the pending block from the feeder gateway, then adapts it to the core.Block type.
+// The hash, number and state root of a pending block are not known yet and are left nil.
+func (g *Gateway) BlockPending(ctx context.Context) (*core.Block, error) {
+	response, err := g.client.GetPendingBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return AdaptBlock(response)
+}
+
+// BlockLatest gets the most recent block from the feeder gateway, then adapts it to the core.Block type.
+func (g *Gateway) BlockLatest(ctx context.Context) (*core.Block, error) {
+	response, err := g.client.GetLatestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return AdaptBlock(response)
+}
+
 func AdaptBlock(response *clients.Block) (*core.Block, error) {
 	if response == nil {
 		return nil, errors.New("nil client block")
@@ -47,7 +72,7 @@ func AdaptBlock(response *clients.Block) (*core.Block, error) {
 	receipts := make([]*core.TransactionReceipt, len(response.Receipts))
 	for i, txn := range response.Transactions {
 		var err error
-		txns[i], err = adaptTransaction(txn)
+		txns[i], err = AdaptTransaction(txn)
 		if err != nil {
 			return nil, err
 		}
@@ -164,7 +189,7 @@ func (g *Gateway) Transaction(ctx context.Context, transactionHash *felt.Felt) (
 		return nil, err
 	}
 
-	tx, err := adaptTransaction(response.Transaction)
+	tx, err := AdaptTransaction(response.Transaction)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +197,7 @@ func (g *Gateway) Transaction(ctx context.Context, transactionHash *felt.Felt) (
 	return tx, nil
 }
 
-func adaptTransaction(transaction *clients.Transaction) (core.Transaction, error) {
+func AdaptTransaction(transaction *clients.Transaction) (core.Transaction, error) {
 	txType := transaction.Type
 	switch txType {
 	case "DECLARE":
@@ -254,10 +279,10 @@ func (g *Gateway) Class(ctx context.Context, classHash *felt.Felt) (*core.Class,
 		return nil, err
 	}
 
-	return adaptClass(response)
+	return AdaptClass(response)
 }
 
-func adaptClass(response *clients.ClassDefinition) (*core.Class, error) {
+func AdaptClass(response *clients.ClassDefinition) (*core.Class, error) {
 	class := new(core.Class)
 
 	class.Abi = response.Abi
@@ -299,9 +324,34 @@ func adaptClass(response *clients.ClassDefinition) (*core.Class, error) {
 		class.Bytecode = append(class.Bytecode, datum)
 	}
 
+	class.Program, err = compressProgram(&response.Program)
+	if err != nil {
+		return nil, err
+	}
+
 	return class, nil
 }
 
+// compressProgram returns the base64 encoding of the gzip compressed JSON representation of
+// the given program, as expected by the deprecated contract class format of the RPC spec.
+func compressProgram(program *clients.Program) (string, error) {
+	programJson, err := json.Marshal(program)
+	if err != nil {
+		return "", err
+	}
+
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err = gzipWriter.Write(programJson); err != nil {
+		return "", err
+	}
+	if err = gzipWriter.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(compressed.Bytes()), nil
+}
+
 // StateUpdate gets the state update for a given block number from the feeder gateway,
 // then adapts it to the core.StateUpdate type.
 func (g *Gateway) StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error) {
@@ -313,6 +363,32 @@ func (g *Gateway) StateUpdate(ctx context.Context, blockNumber uint64) (*core.St
 	return AdaptStateUpdate(response)
 }
 
+// StateUpdatePending gets the state update of the pending block from the feeder gateway,
+// then adapts it to the core.StateUpdate type.
+func (g *Gateway) StateUpdatePending(ctx context.Context) (*core.StateUpdate, error) {
+	response, err := g.client.GetPendingStateUpdate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return AdaptStateUpdate(response)
+}
+
+// StateUpdatePendingWithBlock gets the state update of the pending block along with the block
+// from the feeder gateway. They are read with separate requests, so the pending block can
+// change in between.
+func (g *Gateway) StateUpdatePendingWithBlock(ctx context.Context) (*core.StateUpdate, *core.Block, error) {
+	block, err := g.BlockPending(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	stateUpdate, err := g.StateUpdatePending(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stateUpdate, block, nil
+}
+
 func AdaptStateUpdate(response *clients.StateUpdate) (*core.StateUpdate, error) {
 	stateDiff := new(core.StateDiff)
 	stateDiff.DeclaredClasses = response.StateDiff.DeclaredContracts
@@ -1,7 +1,11 @@
 package gateway
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"encoding/json"
+	"io"
 	"os"
 	"testing"
 
@@ -175,7 +179,7 @@ func TestAdaptClass(t *testing.T) {
 	err = json.Unmarshal(classJson, response)
 	assert.NoError(t, err)
 
-	class, err := adaptClass(response)
+	class, err := AdaptClass(response)
 	assert.NoError(t, err)
 
 	for i, v := range response.EntryPoints.External {
@@ -211,6 +215,17 @@ func TestAdaptClass(t *testing.T) {
 	programHash, err := clients.ProgramHash(response)
 	assert.NoError(t, err)
 	assert.Equal(t, programHash, class.ProgramHash)
+
+	compressedProgram, err := base64.StdEncoding.DecodeString(class.Program)
+	require.NoError(t, err)
+	gzipReader, err := gzip.NewReader(bytes.NewReader(compressedProgram))
+	require.NoError(t, err)
+	programJson, err := io.ReadAll(gzipReader)
+	require.NoError(t, err)
+	program := new(clients.Program)
+	require.NoError(t, json.Unmarshal(programJson, program))
+	assert.Equal(t, response.Program.Data, program.Data)
+	assert.Equal(t, response.Program.Builtins, program.Builtins)
 }
 
 func TestAdaptTransaction(t *testing.T) {
@@ -223,7 +238,7 @@ func TestAdaptTransaction(t *testing.T) {
 		require.NoError(t, err)
 
 		transaction := response.Transaction
-		txn, err := adaptTransaction(transaction)
+		txn, err := AdaptTransaction(transaction)
 		invokeTx, ok := txn.(*core.InvokeTransaction)
 		require.True(t, ok)
 		require.NoError(t, err)
@@ -246,7 +261,7 @@ func TestAdaptTransaction(t *testing.T) {
 		require.NoError(t, err)
 
 		transaction := response.Transaction
-		txn, err := adaptTransaction(transaction)
+		txn, err := AdaptTransaction(transaction)
 		deployTx, ok := txn.(*core.DeployTransaction)
 		require.True(t, ok)
 		require.NoError(t, err)
@@ -270,7 +285,7 @@ func TestAdaptTransaction(t *testing.T) {
 		require.NoError(t, err)
 
 		transaction := response.Transaction
-		txn, err := adaptTransaction(transaction)
+		txn, err := AdaptTransaction(transaction)
 		deployAccountTx, ok := txn.(*core.DeployAccountTransaction)
 		require.True(t, ok)
 		require.NoError(t, err)
@@ -295,7 +310,7 @@ func TestAdaptTransaction(t *testing.T) {
 		require.NoError(t, err)
 
 		transaction := response.Transaction
-		txn, err := adaptTransaction(transaction)
+		txn, err := AdaptTransaction(transaction)
 		declareTx, ok := txn.(*core.DeclareTransaction)
 		require.True(t, ok)
 		require.NoError(t, err)
@@ -320,7 +335,7 @@ func TestAdaptTransaction(t *testing.T) {
 		require.NoError(t, err)
 
 		transaction := response.Transaction
-		txn, err := adaptTransaction(transaction)
+		txn, err := AdaptTransaction(transaction)
 		l1HandlerTx, ok := txn.(*core.L1HandlerTransaction)
 		require.True(t, ok)
 		require.NoError(t, err)
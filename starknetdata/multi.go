@@ -0,0 +1,422 @@
+package starknetdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	stdsync "sync"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/utils"
+)
+
+// unhealthyAfter is the number of consecutive failures of a method after which a source is
+// unhealthy for that method. Sources are only queried for the methods they are unhealthy for once
+// the healthy ones fail, until they succeed again.
+const unhealthyAfter = 3
+
+// The methods that the health of a source is tracked for
+const (
+	methodBlockByNumber               = "BlockByNumber"
+	methodStateUpdate                 = "StateUpdate"
+	methodTransaction                 = "Transaction"
+	methodClass                       = "Class"
+	methodBlockLatest                 = "BlockLatest"
+	methodBlockPending                = "BlockPending"
+	methodStateUpdatePending          = "StateUpdatePending"
+	methodStateUpdatePendingWithBlock = "StateUpdatePendingWithBlock"
+)
+
+var ErrNoQuorum = errors.New("not enough sources agree")
+
+// Source is a named StarknetData that a [Multi] reads from
+type Source struct {
+	Name string
+	StarknetData
+}
+
+// SourceHealth reports how a source of a [Multi] has been doing
+type SourceHealth struct {
+	Name string `json:"name"`
+	// Healthy is false if the source is unhealthy for any method
+	Healthy bool `json:"healthy"`
+	// Requests is the number of requests sent to the source
+	Requests uint64 `json:"requests"`
+	// Failures is the number of requests that failed
+	Failures uint64 `json:"failures"`
+	// Mismatches is the number of blocks and state updates that disagreed with the other sources
+	Mismatches uint64 `json:"mismatches"`
+	// UnhealthyMethods are the StarknetData methods that failed too many times in a row
+	UnhealthyMethods []string `json:"unhealthy_methods,omitempty"`
+	LastError        string   `json:"last_error,omitempty"`
+}
+
+type source struct {
+	Source
+	health SourceHealth
+	// consecutiveFailures are the failures in a row of every method
+	consecutiveFailures map[string]uint64
+}
+
+func (s *source) healthy(method string) bool {
+	return s.consecutiveFailures[method] < unhealthyAfter
+}
+
+// Multi is a StarknetData that reads from several sources. Sources are queried in the order they
+// are given, and the next one is tried when a source fails, so that the data is available as long
+// as one of them is.
+//
+// The latest block is the highest one of all the sources, so that a source that is behind, such
+// as an archive, does not hold the chain back. The pending block and state update are read from
+// the source that had the highest latest block first, and StateUpdatePendingWithBlock reads both
+// of them from the same source.
+//
+// With a quorum, blocks and state updates are only returned once that many sources agree on the
+// block hash and the state root. The latest and pending blocks can legitimately differ between
+// sources and are never cross-checked.
+type Multi struct {
+	quorum int
+	log    utils.SimpleLogger
+
+	lock    stdsync.Mutex
+	sources []*source
+	// tip is the source that returned the highest latest block last
+	tip *source
+}
+
+func NewMulti(sources []Source) *Multi {
+	m := &Multi{
+		quorum:  1,
+		log:     utils.NewNopZapLogger(),
+		sources: make([]*source, len(sources)),
+	}
+	for i, s := range sources {
+		m.sources[i] = &source{Source: s, health: SourceHealth{Name: s.Name}, consecutiveFailures: make(map[string]uint64)}
+	}
+	return m
+}
+
+// WithQuorum sets the number of sources that must agree on a block or a state update, it panics
+// if there are fewer sources than that
+func (m *Multi) WithQuorum(quorum int) *Multi {
+	if quorum > len(m.sources) {
+		panic(fmt.Sprintf("quorum of %d with %d sources", quorum, len(m.sources)))
+	}
+	if quorum < 1 {
+		quorum = 1
+	}
+	m.quorum = quorum
+	return m
+}
+
+// WithLogger sets the logger that source failures are reported to
+func (m *Multi) WithLogger(log utils.SimpleLogger) *Multi {
+	m.log = log
+	return m
+}
+
+// Health returns the health of every source, in the order they were given
+func (m *Multi) Health() []SourceHealth {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	health := make([]SourceHealth, len(m.sources))
+	for i, s := range m.sources {
+		health[i] = s.health
+		for method := range s.consecutiveFailures {
+			if !s.healthy(method) {
+				health[i].UnhealthyMethods = append(health[i].UnhealthyMethods, method)
+			}
+		}
+		sort.Strings(health[i].UnhealthyMethods)
+		health[i].Healthy = len(health[i].UnhealthyMethods) == 0
+	}
+	return health
+}
+
+// ordered returns the sources in the order they should be queried for method: the sources that
+// are healthy for it first, in the order they were given
+func (m *Multi) ordered(method string) []*source {
+	healthy, unhealthy := m.partition(method)
+	return append(healthy, unhealthy...)
+}
+
+// partition splits the sources into the ones that are healthy for method and the others
+func (m *Multi) partition(method string) (healthy, unhealthy []*source) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, s := range m.sources {
+		if s.healthy(method) {
+			healthy = append(healthy, s)
+		} else {
+			unhealthy = append(unhealthy, s)
+		}
+	}
+	return healthy, unhealthy
+}
+
+// record updates the health of s after a request for method returned err. Requests that fail
+// because ctx is done are not the fault of the source and are not recorded.
+func (m *Multi) record(ctx context.Context, s *source, method string, err error) {
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s.health.Requests++
+	if err == nil {
+		if !s.healthy(method) {
+			m.log.Infow("Source recovered", "source", s.Name, "method", method)
+		}
+		delete(s.consecutiveFailures, method)
+		return
+	}
+
+	s.health.Failures++
+	s.health.LastError = err.Error()
+	s.consecutiveFailures[method]++
+	m.log.Debugw("Source request failed", "source", s.Name, "method", method, "err", err)
+	if s.consecutiveFailures[method] == unhealthyAfter {
+		m.log.Warnw("Source is unhealthy", "source", s.Name, "method", method, "err", err)
+	}
+}
+
+func (m *Multi) recordMismatch(s *source) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s.health.Mismatches++
+	m.log.Warnw("Source disagrees with the other sources", "source", s.Name)
+}
+
+// first returns the result of the first of sources that succeeds
+func first[T any](ctx context.Context, m *Multi, method string, sources []*source,
+	get func(StarknetData) (T, error),
+) (T, error) {
+	var zero T
+	var failures []string
+	for _, s := range sources {
+		result, err := get(s.StarknetData)
+		m.record(ctx, s, method, err)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+		failures = append(failures, s.Name+": "+err.Error())
+	}
+	return zero, allFailed(failures)
+}
+
+func allFailed(failures []string) error {
+	return fmt.Errorf("all sources failed: %s", strings.Join(failures, "; "))
+}
+
+type response[T any] struct {
+	source *source
+	result T
+	err    error
+}
+
+// agree returns a result once quorum sources returned results with the same key. It queries as
+// many sources as needed to reach the quorum, and more only if some of them fail or disagree.
+func agree[T any](ctx context.Context, m *Multi, method string, get func(StarknetData) (T, error),
+	key func(T) string,
+) (T, error) {
+	sources := m.ordered(method)
+	if m.quorum == 1 {
+		return first(ctx, m, method, sources, get)
+	}
+
+	responses := make(chan response[T], len(sources))
+	next, inFlight := 0, 0
+	query := func() {
+		s := sources[next]
+		next++
+		inFlight++
+		go func() {
+			result, err := get(s.StarknetData)
+			responses <- response[T]{source: s, result: result, err: err}
+		}()
+	}
+
+	var zero T
+	votes := make(map[string]int)
+	agreed := make(map[string][]*source)
+	maxVotes := 0
+	for {
+		for maxVotes+inFlight < m.quorum && next < len(sources) {
+			query()
+		}
+		if inFlight == 0 {
+			if ctx.Err() != nil {
+				return zero, ctx.Err()
+			}
+			return zero, ErrNoQuorum
+		}
+
+		r := <-responses
+		inFlight--
+		m.record(ctx, r.source, method, r.err)
+		if r.err != nil {
+			continue
+		}
+
+		k := key(r.result)
+		votes[k]++
+		agreed[k] = append(agreed[k], r.source)
+		if votes[k] > maxVotes {
+			maxVotes = votes[k]
+		}
+		if votes[k] >= m.quorum {
+			for other, disagreeing := range agreed {
+				if other != k {
+					for _, s := range disagreeing {
+						m.recordMismatch(s)
+					}
+				}
+			}
+			return r.result, nil
+		}
+	}
+}
+
+func blockKey(block *core.Block) string {
+	return block.Hash.String() + "/" + block.GlobalStateRoot.String()
+}
+
+func stateUpdateKey(update *core.StateUpdate) string {
+	return update.BlockHash.String() + "/" + update.NewRoot.String()
+}
+
+func (m *Multi) BlockByNumber(ctx context.Context, blockNumber uint64) (*core.Block, error) {
+	return agree(ctx, m, methodBlockByNumber, func(data StarknetData) (*core.Block, error) {
+		return data.BlockByNumber(ctx, blockNumber)
+	}, blockKey)
+}
+
+func (m *Multi) StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error) {
+	return agree(ctx, m, methodStateUpdate, func(data StarknetData) (*core.StateUpdate, error) {
+		return data.StateUpdate(ctx, blockNumber)
+	}, stateUpdateKey)
+}
+
+func (m *Multi) Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error) {
+	return first(ctx, m, methodTransaction, m.ordered(methodTransaction), func(data StarknetData) (core.Transaction, error) {
+		return data.Transaction(ctx, transactionHash)
+	})
+}
+
+func (m *Multi) Class(ctx context.Context, classHash *felt.Felt) (*core.Class, error) {
+	return first(ctx, m, methodClass, m.ordered(methodClass), func(data StarknetData) (*core.Class, error) {
+		return data.Class(ctx, classHash)
+	})
+}
+
+// BlockLatest returns the highest latest block of the sources that are healthy for it, or of the
+// other sources if none of them succeeds
+func (m *Multi) BlockLatest(ctx context.Context) (*core.Block, error) {
+	healthy, unhealthy := m.partition(methodBlockLatest)
+
+	var failures []string
+	for _, group := range [][]*source{healthy, unhealthy} {
+		block, groupFailures := m.highest(ctx, group)
+		if block != nil {
+			return block, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		failures = append(failures, groupFailures...)
+	}
+	return nil, allFailed(failures)
+}
+
+// highest queries the latest block of all the sources at once and returns the highest one,
+// which is nil if they all failed
+func (m *Multi) highest(ctx context.Context, sources []*source) (*core.Block, []string) {
+	responses := make(chan response[*core.Block], len(sources))
+	for _, s := range sources {
+		s := s
+		go func() {
+			block, err := s.BlockLatest(ctx)
+			responses <- response[*core.Block]{source: s, result: block, err: err}
+		}()
+	}
+
+	var highest response[*core.Block]
+	var failures []string
+	for range sources {
+		r := <-responses
+		m.record(ctx, r.source, methodBlockLatest, r.err)
+		if r.err != nil {
+			failures = append(failures, r.source.Name+": "+r.err.Error())
+		} else if highest.result == nil || r.result.Number > highest.result.Number {
+			highest = r
+		}
+	}
+
+	if highest.result != nil {
+		m.lock.Lock()
+		m.tip = highest.source
+		m.lock.Unlock()
+	}
+	return highest.result, failures
+}
+
+// atTip returns the sources in the order they should be queried for method, starting with the
+// source that returned the highest latest block if it is healthy for method
+func (m *Multi) atTip(method string) []*source {
+	sources := m.ordered(method)
+
+	m.lock.Lock()
+	tip := m.tip
+	tipHealthy := tip != nil && tip.healthy(method)
+	m.lock.Unlock()
+	if !tipHealthy {
+		return sources
+	}
+
+	ordered := []*source{tip}
+	for _, s := range sources {
+		if s != tip {
+			ordered = append(ordered, s)
+		}
+	}
+	return ordered
+}
+
+func (m *Multi) BlockPending(ctx context.Context) (*core.Block, error) {
+	return first(ctx, m, methodBlockPending, m.atTip(methodBlockPending), func(data StarknetData) (*core.Block, error) {
+		return data.BlockPending(ctx)
+	})
+}
+
+func (m *Multi) StateUpdatePending(ctx context.Context) (*core.StateUpdate, error) {
+	return first(ctx, m, methodStateUpdatePending, m.atTip(methodStateUpdatePending),
+		func(data StarknetData) (*core.StateUpdate, error) {
+			return data.StateUpdatePending(ctx)
+		})
+}
+
+// pendingWithBlock is a pending state update along with the pending block
+type pendingWithBlock struct {
+	stateUpdate *core.StateUpdate
+	block       *core.Block
+}
+
+func (m *Multi) StateUpdatePendingWithBlock(ctx context.Context) (*core.StateUpdate, *core.Block, error) {
+	pending, err := first(ctx, m, methodStateUpdatePendingWithBlock, m.atTip(methodStateUpdatePendingWithBlock),
+		func(data StarknetData) (pendingWithBlock, error) {
+			stateUpdate, block, err := data.StateUpdatePendingWithBlock(ctx)
+			return pendingWithBlock{stateUpdate: stateUpdate, block: block}, err
+		})
+	return pending.stateUpdate, pending.block, err
+}
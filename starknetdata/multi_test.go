@@ -0,0 +1,205 @@
+package starknetdata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/testsource"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMulti(t *testing.T) {
+	gw, closeFn := testsource.NewTestGateway(utils.MAINNET)
+	defer closeFn()
+	ctx := context.Background()
+
+	want, err := gw.BlockByNumber(ctx, 1)
+	require.NoError(t, err)
+	wantUpdate, err := gw.StateUpdate(ctx, 1)
+	require.NoError(t, err)
+
+	t.Run("fail over to the next source", func(t *testing.T) {
+		multi := starknetdata.NewMulti([]starknetdata.Source{
+			{Name: "down", StarknetData: &failingSource{StarknetData: gw}},
+			{Name: "gateway", StarknetData: gw},
+		})
+
+		for i := 0; i < 3; i++ {
+			block, err := multi.BlockByNumber(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, want, block)
+		}
+		health := multi.Health()
+		assert.Equal(t, starknetdata.SourceHealth{
+			Name:             "down",
+			Requests:         3,
+			Failures:         3,
+			UnhealthyMethods: []string{"BlockByNumber"},
+			LastError:        "source is down",
+		}, health[0])
+		assert.Equal(t, starknetdata.SourceHealth{Name: "gateway", Healthy: true, Requests: 3}, health[1])
+
+		// unhealthy sources are queried last, but only for the methods that keep failing
+		_, err := multi.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), multi.Health()[0].Requests)
+		_, err = multi.StateUpdate(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), multi.Health()[0].Requests)
+	})
+	t.Run("the latest block is the highest of all sources", func(t *testing.T) {
+		multi := starknetdata.NewMulti([]starknetdata.Source{
+			{Name: "archive", StarknetData: &staleSource{StarknetData: gw}},
+			{Name: "gateway", StarknetData: gw},
+		})
+
+		wantLatest, err := gw.BlockLatest(ctx)
+		require.NoError(t, err)
+		latest, err := multi.BlockLatest(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, wantLatest, latest)
+
+		// the pending block is read from the source with the highest latest block
+		wantPending, err := gw.BlockPending(ctx)
+		require.NoError(t, err)
+		pending, err := multi.BlockPending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, wantPending, pending)
+		assert.Equal(t, uint64(1), multi.Health()[0].Requests)
+
+		// and so is the pending state update along with the pending block
+		wantPendingUpdate, err := gw.StateUpdatePending(ctx)
+		require.NoError(t, err)
+		pendingUpdate, pending, err := multi.StateUpdatePendingWithBlock(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, wantPendingUpdate, pendingUpdate)
+		assert.Equal(t, wantPending, pending)
+		assert.Equal(t, uint64(1), multi.Health()[0].Requests)
+	})
+	t.Run("failures of one method do not affect the others", func(t *testing.T) {
+		multi := starknetdata.NewMulti([]starknetdata.Source{
+			{Name: "archive", StarknetData: &staleSource{StarknetData: gw}},
+			{Name: "gateway", StarknetData: gw},
+		})
+
+		for i := 0; i < 3; i++ {
+			_, err := multi.BlockPending(ctx)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []string{"BlockPending"}, multi.Health()[0].UnhealthyMethods)
+
+		_, err := multi.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+		health := multi.Health()
+		assert.Equal(t, uint64(4), health[0].Requests)
+		assert.Equal(t, uint64(3), health[1].Requests)
+	})
+	t.Run("all sources fail", func(t *testing.T) {
+		multi := starknetdata.NewMulti([]starknetdata.Source{
+			{Name: "down", StarknetData: &failingSource{StarknetData: gw}},
+			{Name: "gateway", StarknetData: gw},
+		})
+		_, err := multi.BlockByNumber(ctx, 12345678)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "down: source is down")
+	})
+	t.Run("cancelled requests do not count against the sources", func(t *testing.T) {
+		multi := starknetdata.NewMulti([]starknetdata.Source{{Name: "gateway", StarknetData: gw}})
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := multi.BlockLatest(cancelled)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, starknetdata.SourceHealth{Name: "gateway", Healthy: true}, multi.Health()[0])
+	})
+	t.Run("quorum", func(t *testing.T) {
+		multi := starknetdata.NewMulti([]starknetdata.Source{
+			{Name: "gateway", StarknetData: gw},
+			{Name: "liar", StarknetData: &lyingSource{StarknetData: gw}},
+			{Name: "mirror", StarknetData: gw},
+		}).WithQuorum(2)
+
+		block, err := multi.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, want, block)
+		update, err := multi.StateUpdate(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, wantUpdate, update)
+
+		health := multi.Health()
+		assert.Equal(t, uint64(2), health[1].Mismatches)
+		assert.Zero(t, health[0].Mismatches)
+		assert.Zero(t, health[2].Mismatches)
+	})
+	t.Run("no quorum", func(t *testing.T) {
+		multi := starknetdata.NewMulti([]starknetdata.Source{
+			{Name: "gateway", StarknetData: gw},
+			{Name: "liar", StarknetData: &lyingSource{StarknetData: gw}},
+			{Name: "down", StarknetData: &failingSource{StarknetData: gw}},
+		}).WithQuorum(2)
+
+		_, err := multi.BlockByNumber(ctx, 1)
+		assert.ErrorIs(t, err, starknetdata.ErrNoQuorum)
+	})
+	t.Run("quorum larger than the number of sources", func(t *testing.T) {
+		assert.Panics(t, func() {
+			starknetdata.NewMulti([]starknetdata.Source{{Name: "gateway", StarknetData: gw}}).WithQuorum(2)
+		})
+	})
+}
+
+// failingSource fails every request
+type failingSource struct {
+	starknetdata.StarknetData
+}
+
+var errDown = errors.New("source is down")
+
+func (s *failingSource) BlockByNumber(context.Context, uint64) (*core.Block, error) {
+	return nil, errDown
+}
+
+func (s *failingSource) StateUpdate(context.Context, uint64) (*core.StateUpdate, error) {
+	return nil, errDown
+}
+
+// staleSource is an archive that stops at block 0 and has no pending block
+type staleSource struct {
+	starknetdata.StarknetData
+}
+
+func (s *staleSource) BlockLatest(ctx context.Context) (*core.Block, error) {
+	return s.StarknetData.BlockByNumber(ctx, 0)
+}
+
+func (s *staleSource) BlockPending(context.Context) (*core.Block, error) {
+	return nil, errors.New("no pending block")
+}
+
+// lyingSource returns blocks and state updates of a different chain
+type lyingSource struct {
+	starknetdata.StarknetData
+}
+
+func (s *lyingSource) BlockByNumber(ctx context.Context, number uint64) (*core.Block, error) {
+	block, err := s.StarknetData.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	block.GlobalStateRoot = new(felt.Felt).SetUint64(1)
+	return block, nil
+}
+
+func (s *lyingSource) StateUpdate(ctx context.Context, number uint64) (*core.StateUpdate, error) {
+	update, err := s.StarknetData.StateUpdate(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	update.NewRoot = new(felt.Felt).SetUint64(1)
+	return update, nil
+}
@@ -13,4 +13,10 @@ type StarknetData interface {
 	Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error)
 	Class(ctx context.Context, classHash *felt.Felt) (*core.Class, error)
 	StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error)
+	BlockLatest(ctx context.Context) (*core.Block, error)
+	BlockPending(ctx context.Context) (*core.Block, error)
+	StateUpdatePending(ctx context.Context) (*core.StateUpdate, error)
+	// StateUpdatePendingWithBlock returns the pending state update along with the pending
+	// block, both read from the same source
+	StateUpdatePendingWithBlock(ctx context.Context) (*core.StateUpdate, *core.Block, error)
 }
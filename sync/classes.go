@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	stdsync "sync"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/starknetdata"
+)
+
+// classCall is a class fetch that is in flight, its result is shared by everyone waiting on
+// done
+type classCall struct {
+	done  chan struct{}
+	class *core.Class
+	err   error
+}
+
+// classFetcher merges concurrent fetches of the same class into a single request, so that the
+// fetchers of neighbouring blocks referencing a popular class do not all download it.
+type classFetcher struct {
+	lock     stdsync.Mutex
+	inFlight map[felt.Felt]*classCall
+}
+
+func newClassFetcher() *classFetcher {
+	return &classFetcher{inFlight: make(map[felt.Felt]*classCall)}
+}
+
+// fetch returns the class with the given hash, joining the fetch of another caller if there
+// is one in flight. Failed fetches are not cached, the next call tries again.
+func (f *classFetcher) fetch(ctx context.Context, source starknetdata.StarknetData, classHash *felt.Felt) (*core.Class, error) {
+	f.lock.Lock()
+	call, found := f.inFlight[*classHash]
+	if !found {
+		call = &classCall{done: make(chan struct{})}
+		f.inFlight[*classHash] = call
+	}
+	f.lock.Unlock()
+
+	if !found {
+		call.class, call.err = source.Class(ctx, classHash)
+		f.lock.Lock()
+		delete(f.inFlight, *classHash)
+		f.lock.Unlock()
+		close(call.done)
+	}
+
+	select {
+	case <-call.done:
+		return call.class, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchReferencedClasses fetches the classes that are declared or deployed by the given state
+// update and are not stored yet. An error is returned if any of them cannot be fetched.
+func (s *Synchronizer) fetchReferencedClasses(ctx context.Context, stateUpdate *core.StateUpdate) (map[felt.Felt]*core.Class, error) {
+	// There are classes in deployed transactions which refer to class hash that are no present in declared
+	// classes. Thus, we need to fetch all the classes which are referenced in deployed contracts
+	referencedClasses := make(map[felt.Felt]*core.Class)
+	for _, deployedContract := range stateUpdate.StateDiff.DeployedContracts {
+		referencedClasses[*deployedContract.ClassHash] = nil
+	}
+	for _, classHash := range stateUpdate.StateDiff.DeclaredClasses {
+		referencedClasses[*classHash] = nil
+	}
+
+	for classHash := range referencedClasses {
+		classHash := classHash
+		stored, err := s.Blockchain.HasClass(&classHash)
+		if err != nil {
+			return nil, err
+		}
+		if stored {
+			// most deployments are of a handful of account classes, only the first one needs
+			// to be fetched
+			delete(referencedClasses, classHash)
+			continue
+		}
+
+		class, err := s.classes.fetch(ctx, s.StarknetData, &classHash)
+		if err != nil {
+			return nil, fmt.Errorf("fetch class %s: %w", classHash.ShortString(), err)
+		}
+		referencedClasses[classHash] = class
+	}
+	return referencedClasses, nil
+}
@@ -0,0 +1,63 @@
+package sync
+
+import stdsync "sync"
+
+// feedBufferSize is the number of values a Subscription buffers. Values sent while its
+// buffer is full are dropped, so that a slow subscriber never holds up the Synchronizer.
+const feedBufferSize = 16
+
+// Subscription receives the values sent on a feed from the moment it is created until
+// it is unsubscribed.
+type Subscription[T any] struct {
+	C <-chan T
+
+	feed *feed[T]
+	ch   chan T
+	once stdsync.Once
+}
+
+// Unsubscribe stops the delivery of values and closes C
+func (s *Subscription[T]) Unsubscribe() {
+	s.once.Do(func() {
+		s.feed.remove(s.ch)
+	})
+}
+
+// feed broadcasts values to all its subscriptions. The zero value is ready to use.
+type feed[T any] struct {
+	lock stdsync.Mutex
+	subs map[chan T]struct{}
+}
+
+func (f *feed[T]) subscribe() *Subscription[T] {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.subs == nil {
+		f.subs = make(map[chan T]struct{})
+	}
+	ch := make(chan T, feedBufferSize)
+	f.subs[ch] = struct{}{}
+	return &Subscription[T]{C: ch, feed: f, ch: ch}
+}
+
+func (f *feed[T]) remove(ch chan T) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.subs, ch)
+	close(ch)
+}
+
+// send delivers value to every subscription that has room for it
+func (f *feed[T]) send(value T) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for ch := range f.subs {
+		select {
+		case ch <- value:
+		default:
+		}
+	}
+}
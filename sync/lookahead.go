@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"sync/atomic"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/encoder"
+)
+
+// lookahead bounds how far the fetched blocks can run ahead of the stored head, so that the
+// memory held by the blocks waiting to be stored stays bounded. A zero limit means no limit.
+type lookahead struct {
+	maxBlocks uint64
+	maxBytes  uint64
+
+	// bytes is the size of the blocks that are fetched but not stored or dropped yet
+	bytes uint64
+	// freed is signalled every time a block leaves the window
+	freed chan struct{}
+}
+
+func newLookahead(maxBlocks, maxBytes uint64) *lookahead {
+	return &lookahead{
+		maxBlocks: maxBlocks,
+		maxBytes:  maxBytes,
+		freed:     make(chan struct{}, 1),
+	}
+}
+
+// full reports whether fetching the block with the given number would exceed the window,
+// nextToStore is the number of the first block that is not stored yet.
+//
+// The byte limit is soft: the blocks that are being fetched are only accounted for once they
+// are, so it can be overshot by the size of the blocks fetched concurrently.
+func (l *lookahead) full(number, nextToStore uint64) bool {
+	if l.maxBlocks > 0 && number >= nextToStore && number-nextToStore >= l.maxBlocks {
+		return true
+	}
+	return l.maxBytes > 0 && atomic.LoadUint64(&l.bytes) >= l.maxBytes
+}
+
+// add accounts for a fetched block and returns its size, which must be passed to remove once
+// the block leaves the window
+func (l *lookahead) add(block *core.Block, stateUpdate *core.StateUpdate, classes map[felt.Felt]*core.Class) uint64 {
+	if l.maxBytes == 0 {
+		return 0
+	}
+	size := encodedSize(block) + encodedSize(stateUpdate)
+	for _, class := range classes {
+		size += encodedSize(class)
+	}
+	atomic.AddUint64(&l.bytes, size)
+	return size
+}
+
+// remove takes a block of the given size out of the window
+func (l *lookahead) remove(size uint64) {
+	if size > 0 {
+		atomic.AddUint64(&l.bytes, ^(size - 1))
+	}
+	select {
+	case l.freed <- struct{}{}:
+	default:
+	}
+}
+
+// encodedSize is the size of v as it is stored, which is used as an estimate of the memory
+// it takes
+func encodedSize(v any) uint64 {
+	encoded, err := encoder.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return uint64(len(encoded))
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"sync/atomic"
+	"time"
 
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/core"
@@ -23,69 +25,159 @@ func (e ErrSyncFailed) Error() string {
 	return fmt.Sprintf("Sync failed on block #%d with %s", e.Height, e.Err.Error())
 }
 
+// ErrRevertFailed is returned by SyncBlocks when the head could not be reverted after a reorg.
+// Syncing stops since it cannot get past the reorg without reverting the head.
+type ErrRevertFailed struct {
+	Height uint64
+	Err    error
+}
+
+func (e ErrRevertFailed) Error() string {
+	return fmt.Sprintf("Failed reverting block #%d after a reorg: %s", e.Height, e.Err.Error())
+}
+
+func (e ErrRevertFailed) Unwrap() error {
+	return e.Err
+}
+
+const (
+	defaultPendingPollInterval = 5 * time.Second
+	defaultHeadPollInterval    = 5 * time.Second
+	defaultFetchRetryInterval  = time.Second
+	// pendingFetchAttempts is the number of times the pending block and its state update are
+	// read before giving up until the next poll if they keep disagreeing
+	pendingFetchAttempts = 3
+)
+
+// Reader exposes the progress of the Synchronizer
+type Reader interface {
+	StartingBlockNumber() (uint64, bool)
+	HighestBlockHeader() *core.Header
+	SubscribeNewHeads() *Subscription[*core.Header]
+	SubscribePending() *Subscription[*blockchain.Pending]
+}
+
+// EventListener is notified of the progress of the Synchronizer
+type EventListener interface {
+	// OnBlockStored is called after every block the Synchronizer stores
+	OnBlockStored(number uint64)
+}
+
 // Synchronizer manages a list of StarknetData to fetch the latest blockchain updates
 type Synchronizer struct {
 	Blockchain   *blockchain.Blockchain
 	StarknetData starknetdata.StarknetData
 
-	log utils.SimpleLogger
+	pendingPollInterval time.Duration
+	headPollInterval    time.Duration
+	fetchRetryInterval  time.Duration
+	log                 utils.SimpleLogger
+	listener            EventListener
+	classes             *classFetcher
+	lookahead           *lookahead
+
+	startingBlockNumber atomic.Value // uint64
+	highestBlockHeader  atomic.Value // *core.Header
+
+	newHeads feed[*core.Header]
+	pending  feed[*blockchain.Pending]
 }
 
 func NewSynchronizer(bc *blockchain.Blockchain, starkNetData starknetdata.StarknetData, log utils.SimpleLogger) *Synchronizer {
 	return &Synchronizer{
-		Blockchain:   bc,
-		StarknetData: starkNetData,
-		log:          log,
+		Blockchain:          bc,
+		StarknetData:        starkNetData,
+		pendingPollInterval: defaultPendingPollInterval,
+		headPollInterval:    defaultHeadPollInterval,
+		fetchRetryInterval:  defaultFetchRetryInterval,
+		log:                 log,
+		classes:             newClassFetcher(),
+		lookahead:           newLookahead(0, 0),
 	}
 }
 
+// WithPendingPollInterval sets how often the pending block is polled
+func (s *Synchronizer) WithPendingPollInterval(interval time.Duration) *Synchronizer {
+	s.pendingPollInterval = interval
+	return s
+}
+
+// WithHeadPollInterval sets how often the latest block is polled once the Synchronizer
+// has caught up with the chain tip
+func (s *Synchronizer) WithHeadPollInterval(interval time.Duration) *Synchronizer {
+	s.headPollInterval = interval
+	return s
+}
+
+// WithListener sets the EventListener that is notified of the progress of the Synchronizer
+func (s *Synchronizer) WithListener(listener EventListener) *Synchronizer {
+	s.listener = listener
+	return s
+}
+
+// WithLookahead bounds how far ahead of the stored head blocks are fetched, by the number of
+// blocks and by the total size in bytes of the blocks waiting to be stored. Zero means no limit.
+func (s *Synchronizer) WithLookahead(maxBlocks, maxBytes uint64) *Synchronizer {
+	s.lookahead = newLookahead(maxBlocks, maxBytes)
+	return s
+}
+
 // Run starts the Synchronizer, returns an error if the loop is already running
 func (s *Synchronizer) Run(ctx context.Context) error {
 	return s.SyncBlocks(ctx)
 }
 
-func (s *Synchronizer) fetcherTask(ctx context.Context, height uint64, verifiers *stream.Stream, errChan chan ErrSyncFailed) stream.Callback {
+func (s *Synchronizer) fetcherTask(ctx context.Context, height uint64, verifiers *stream.Stream, fail func(ErrSyncFailed)) stream.Callback {
 	for {
+		block, stateUpdate, referencedClasses, err := s.fetchBlock(ctx, height)
+		if err == nil {
+			size := s.lookahead.add(block, stateUpdate, referencedClasses)
+			return func() {
+				verifiers.Go(func() stream.Callback {
+					return s.verifierTask(ctx, block, stateUpdate, referencedClasses, size, fail)
+				})
+			}
+		}
+
+		if ctx.Err() != nil {
+			return func() {}
+		}
+		s.log.Debugw("Failed fetching block, retrying", "number", height, "err", err.Error())
 		select {
 		case <-ctx.Done():
 			return func() {}
-		default:
-			block, err := s.StarknetData.BlockByNumber(ctx, height)
-			if err != nil {
-				continue
-			}
-			stateUpdate, err := s.StarknetData.StateUpdate(ctx, height)
-			if err != nil {
-				continue
-			}
-
-			// There are classes in deployed transactions which refer to class hash that are no present in declared
-			// classes. Thus, we need to fetch all the classes which are referenced in deployed contracts
-			referencedClasses := make(map[felt.Felt]*core.Class)
-			for _, deployedContract := range stateUpdate.StateDiff.DeployedContracts {
-				referencedClasses[*deployedContract.ClassHash] = nil
-			}
-			for _, classHash := range stateUpdate.StateDiff.DeclaredClasses {
-				referencedClasses[*classHash] = nil
-			}
-			for classHash := range referencedClasses {
-				class, err := s.StarknetData.Class(ctx, &classHash)
-				if err != nil {
-					continue
-				}
-				referencedClasses[classHash] = class
-			}
-
-			return func() {
-				verifiers.Go(func() stream.Callback { return s.verifierTask(ctx, block, stateUpdate, referencedClasses, errChan) })
-			}
+		case <-time.After(s.fetchRetryInterval):
 		}
 	}
 }
 
-func (s *Synchronizer) verifierTask(ctx context.Context, block *core.Block, stateUpdate *core.StateUpdate, declaredClasses map[felt.Felt]*core.Class, errChan chan ErrSyncFailed) stream.Callback {
+// fetchBlock fetches the block with the given number along with its state update and the
+// classes it references that are not stored yet
+func (s *Synchronizer) fetchBlock(ctx context.Context, height uint64) (*core.Block, *core.StateUpdate,
+	map[felt.Felt]*core.Class, error,
+) {
+	block, err := s.StarknetData.BlockByNumber(ctx, height)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stateUpdate, err := s.StarknetData.StateUpdate(ctx, height)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	referencedClasses, err := s.fetchReferencedClasses(ctx, stateUpdate)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return block, stateUpdate, referencedClasses, nil
+}
+
+// verifierTask checks and stores a fetched block, which takes size bytes of the lookahead window
+func (s *Synchronizer) verifierTask(ctx context.Context, block *core.Block, stateUpdate *core.StateUpdate,
+	declaredClasses map[felt.Felt]*core.Class, size uint64, fail func(ErrSyncFailed),
+) stream.Callback {
 	err := s.Blockchain.SanityCheckNewHeight(block, stateUpdate)
 	return func() {
+		defer s.lookahead.remove(size)
 		select {
 		case <-ctx.Done():
 			return
@@ -98,63 +190,263 @@ func (s *Synchronizer) verifierTask(ctx context.Context, block *core.Block, stat
 					}
 				} else {
 					s.log.Warnw("Sanity checks failed", "number", block.Number, "hash", block.Hash.ShortString())
-					select {
-					case <-ctx.Done():
-					case errChan <- ErrSyncFailed{block.Number, err}:
-					}
+					fail(ErrSyncFailed{block.Number, err})
 					return
 				}
 			}
 			err := s.Blockchain.Store(block, stateUpdate, declaredClasses)
 			if err != nil {
-				s.log.Warnw("Failed storing Block", "number", block.Number,
-					"hash", block.Hash.ShortString(), "err", err.Error())
-				select {
-				case <-ctx.Done():
-				case errChan <- ErrSyncFailed{block.Number, err}:
+				failedHeight := block.Number
+				if errors.Is(err, blockchain.ErrParentDoesNotMatchHead) {
+					// the chain was reorganised: drop our head and walk back until the parent
+					// hashes match again
+					s.log.Warnw("Reorg detected, reverting head", "number", block.Number-1,
+						"parentHash", block.ParentHash.ShortString())
+					failedHeight = block.Number - 1
+					if revertErr := s.Blockchain.RevertHead(); revertErr != nil {
+						// retrying would fail the same way forever, stop syncing instead
+						err = ErrRevertFailed{failedHeight, revertErr}
+					}
+				} else {
+					s.log.Warnw("Failed storing Block", "number", block.Number,
+						"hash", block.Hash.ShortString(), "err", err.Error())
 				}
+				fail(ErrSyncFailed{failedHeight, err})
 				return
 			}
 
 			s.log.Infow("Stored Block", "number", block.Number, "hash",
 				block.Hash.ShortString(), "root", block.GlobalStateRoot.ShortString())
+			s.newHeads.send(&block.Header)
+			if s.listener != nil {
+				s.listener.OnBlockStored(block.Number)
+			}
 		}
 	}
 }
 
-func (s *Synchronizer) SyncBlocks(syncCtx context.Context) error {
-	errChan := make(chan ErrSyncFailed)
+// SyncBlocks keeps storing the blocks of the network until ctx is cancelled. It only returns
+// early with ErrRevertFailed if it cannot revert the head after a reorg.
+func (s *Synchronizer) SyncBlocks(ctx context.Context) error {
+	syncCtx, syncCancel := context.WithCancel(ctx)
+	defer syncCancel()
+
+	// Verifier callbacks run one at a time and a failing one cancels its stream before
+	// reporting, so at most one error is in flight.
+	errChan := make(chan ErrSyncFailed, 1)
 	fetchers := stream.New().WithMaxGoroutines(runtime.NumCPU())
 	verifiers := stream.New().WithMaxGoroutines(runtime.NumCPU())
 
+	pendingDone := make(chan struct{})
+	go func() {
+		s.pollPending(syncCtx)
+		close(pendingDone)
+	}()
+
 	streamCtx, streamCancel := context.WithCancel(syncCtx)
-	nextHeight := uint64(0)
-	if h, err := s.Blockchain.Height(); err == nil {
-		nextHeight = h + 1
+	// fail stops all the tasks of the stream it belongs to and reports the error to the
+	// loop below
+	newFail := func(cancel context.CancelFunc) func(ErrSyncFailed) {
+		return func(err ErrSyncFailed) {
+			cancel()
+			errChan <- err
+		}
+	}
+	fail := newFail(streamCancel)
+	nextHeight := s.nextToStore()
+	s.startingBlockNumber.Store(nextHeight)
+
+	shutdown := func() error {
+		fetchers.Wait()
+		verifiers.Wait()
+		<-pendingDone
+		if errors.Is(syncCtx.Err(), context.Canceled) {
+			return nil
+		}
+		return syncCtx.Err()
+	}
+	// rollback restarts syncing from the failed height, unless the failure is fatal in which
+	// case syncing is shut down and the error is returned
+	rollback := func(err ErrSyncFailed) error {
+		var revertErr ErrRevertFailed
+		if errors.As(err.Err, &revertErr) {
+			s.log.Errorw("Stopping sync", "err", revertErr.Error())
+			syncCancel()
+			if shutdownErr := shutdown(); shutdownErr != nil {
+				return shutdownErr
+			}
+			return revertErr
+		}
+
+		streamCancel() // cancel all running tasks
+		streamCtx, streamCancel = context.WithCancel(syncCtx)
+		fail = newFail(streamCancel)
+		nextHeight = err.Height // keep syncing from failed height
+		s.log.Warnw("Rolling back sync process to failed height", "height", err.Height)
+		return nil
 	}
 
+	headTicker := time.NewTicker(s.headPollInterval)
+	defer headTicker.Stop()
+	s.pollHead(syncCtx)
+
 	for {
+		if highest := s.HighestBlockHeader(); highest == nil || nextHeight > highest.Number {
+			// caught up with the latest known head, only fetch again once a new head shows up
+			select {
+			case err := <-errChan:
+				if fatalErr := rollback(err); fatalErr != nil {
+					return fatalErr
+				}
+			case <-syncCtx.Done():
+				return shutdown()
+			case <-headTicker.C:
+				s.pollHead(syncCtx)
+			}
+			continue
+		}
+
+		if s.lookahead.full(nextHeight, s.nextToStore()) {
+			// wait for the blocks ahead to be stored before fetching more
+			select {
+			case err := <-errChan:
+				if fatalErr := rollback(err); fatalErr != nil {
+					return fatalErr
+				}
+			case <-syncCtx.Done():
+				return shutdown()
+			case <-s.lookahead.freed:
+			}
+			continue
+		}
+
 		select {
 		case err := <-errChan:
-			streamCancel() // cancel all running tasks
-			streamCtx, streamCancel = context.WithCancel(syncCtx)
-			nextHeight = err.Height // keep syncing from failed height
-			s.log.Warnw("Rolling back sync process to failed height", "height", err.Height)
-		case <-syncCtx.Done():
-			fetchers.Wait()
-			verifiers.Wait()
-			if errors.Is(syncCtx.Err(), context.Canceled) {
-				return nil
-			} else {
-				return syncCtx.Err()
+			if fatalErr := rollback(err); fatalErr != nil {
+				return fatalErr
 			}
+		case <-syncCtx.Done():
+			return shutdown()
 		default:
 			curHeight := nextHeight
-			curStreamCtx := streamCtx
+			curStreamCtx, curFail := streamCtx, fail
 			fetchers.Go(func() stream.Callback {
-				return s.fetcherTask(curStreamCtx, curHeight, verifiers, errChan)
+				return s.fetcherTask(curStreamCtx, curHeight, verifiers, curFail)
 			})
 			nextHeight++
 		}
 	}
 }
+
+// nextToStore returns the number of the first block that is not stored yet
+func (s *Synchronizer) nextToStore() uint64 {
+	if h, err := s.Blockchain.Height(); err == nil {
+		return h + 1
+	}
+	return 0
+}
+
+// pollHead fetches the latest block and records it as the highest block known to the network
+func (s *Synchronizer) pollHead(ctx context.Context) {
+	head, err := s.StarknetData.BlockLatest(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Debugw("Failed fetching latest block", "err", err.Error())
+		}
+		return
+	}
+
+	if highest := s.HighestBlockHeader(); highest == nil || !highest.Hash.Equal(head.Hash) {
+		s.log.Debugw("New head", "number", head.Number, "hash", head.Hash.ShortString())
+	}
+	s.highestBlockHeader.Store(&head.Header)
+}
+
+// StartingBlockNumber returns the number of the first block this Synchronizer fetched. If syncing
+// has not started yet, false is returned.
+func (s *Synchronizer) StartingBlockNumber() (uint64, bool) {
+	number, started := s.startingBlockNumber.Load().(uint64)
+	return number, started
+}
+
+// HighestBlockHeader returns the header of the latest block known to the network, or nil if it
+// has not been fetched yet.
+func (s *Synchronizer) HighestBlockHeader() *core.Header {
+	header, _ := s.highestBlockHeader.Load().(*core.Header)
+	return header
+}
+
+// SubscribeNewHeads returns a Subscription to the header of every block stored from now on
+func (s *Synchronizer) SubscribeNewHeads() *Subscription[*core.Header] {
+	return s.newHeads.subscribe()
+}
+
+// SubscribePending returns a Subscription to every pending block stored from now on
+func (s *Synchronizer) SubscribePending() *Subscription[*blockchain.Pending] {
+	return s.pending.subscribe()
+}
+
+// pollPending periodically fetches the pending block and stores it in the Blockchain
+// whenever it is built on top of the current head.
+func (s *Synchronizer) pollPending(ctx context.Context) {
+	ticker := time.NewTicker(s.pendingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.fetchPending(ctx); err != nil {
+				s.log.Debugw("Failed fetching pending block", "err", err.Error())
+			}
+		}
+	}
+}
+
+// fetchPending stores the pending block if it builds on the head. The pending block and its
+// state update are read from the same source, but the pending block can still change between
+// the two reads, so they are read again until the state update also builds on the head.
+func (s *Synchronizer) fetchPending(ctx context.Context) error {
+	head, err := s.Blockchain.Head()
+	if err != nil {
+		// nothing to build on top of yet
+		return nil
+	}
+
+	var block *core.Block
+	var stateUpdate *core.StateUpdate
+	for attempt := 1; ; attempt++ {
+		stateUpdate, block, err = s.StarknetData.StateUpdatePendingWithBlock(ctx)
+		if err != nil {
+			return err
+		}
+		if !block.ParentHash.Equal(head.Hash) {
+			// not at the tip of the chain yet
+			return nil
+		}
+		if stateUpdate.OldRoot.Equal(head.GlobalStateRoot) {
+			break
+		}
+		if attempt == pendingFetchAttempts {
+			return fmt.Errorf("pending state update is based on root %s instead of the root %s of the head",
+				stateUpdate.OldRoot.ShortString(), head.GlobalStateRoot.ShortString())
+		}
+	}
+
+	newClasses, err := s.fetchReferencedClasses(ctx, stateUpdate)
+	if err != nil {
+		return err
+	}
+
+	pending := &blockchain.Pending{
+		Block:       block,
+		StateUpdate: stateUpdate,
+		NewClasses:  newClasses,
+	}
+	if err = s.Blockchain.StorePending(pending); err != nil {
+		return err
+	}
+	s.pending.send(pending)
+	return nil
+}
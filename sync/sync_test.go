@@ -2,11 +2,18 @@ package sync
 
 import (
 	"context"
+	"errors"
+	stdsync "sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/starknetdata"
 	"github.com/NethermindEth/juno/testsource"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/stretchr/testify/assert"
@@ -16,6 +23,7 @@ import (
 func TestSyncBlocks(t *testing.T) {
 	gw, closeFn := testsource.NewTestGateway(utils.MAINNET)
 	defer closeFn()
+	source := testsource.StubClasses(gw)
 	testBlockchain := func(t *testing.T, bc *blockchain.Blockchain) bool {
 		return assert.NoError(t, func() error {
 			headBlock, err := bc.Head()
@@ -41,7 +49,7 @@ func TestSyncBlocks(t *testing.T) {
 	t.Run("sync multiple blocks in an empty db", func(t *testing.T) {
 		testDB := pebble.NewMemTest()
 		bc := blockchain.New(testDB, utils.MAINNET)
-		synchronizer := NewSynchronizer(bc, gw, log)
+		synchronizer := NewSynchronizer(bc, source, log)
 		ctx, cancel := context.WithCancel(context.Background())
 		go func() {
 			time.Sleep(time.Second)
@@ -60,7 +68,7 @@ func TestSyncBlocks(t *testing.T) {
 		require.NoError(t, err)
 		require.NoError(t, bc.Store(b0, s0, nil))
 
-		synchronizer := NewSynchronizer(bc, gw, log)
+		synchronizer := NewSynchronizer(bc, source, log)
 		ctx, cancel := context.WithCancel(context.Background())
 		go func() {
 			time.Sleep(time.Second)
@@ -70,4 +78,503 @@ func TestSyncBlocks(t *testing.T) {
 
 		testBlockchain(t, bc)
 	})
+	t.Run("listener is notified of the progress", func(t *testing.T) {
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+		listener := new(recordingListener)
+		synchronizer := NewSynchronizer(bc, source, log).WithListener(listener)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Second)
+			cancel()
+		}()
+		require.NoError(t, synchronizer.Run(ctx))
+
+		head, err := bc.Head()
+		require.NoError(t, err)
+		assert.Equal(t, head.Number+1, atomic.LoadUint64(&listener.stored))
+	})
+	t.Run("sync pending block once at the tip", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		synchronizer := NewSynchronizer(bc, source, log).WithPendingPollInterval(100 * time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error)
+		go func() {
+			runErr <- synchronizer.Run(ctx)
+		}()
+
+		var head *core.Block
+		var pending *blockchain.Pending
+		require.Eventually(t, func() bool {
+			var err error
+			if head, err = bc.Head(); err != nil {
+				return false
+			}
+			pending, err = bc.Pending()
+			return err == nil && head.Hash.Equal(pending.Block.ParentHash)
+		}, 30*time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-runErr)
+
+		assert.Equal(t, head.GlobalStateRoot, pending.StateUpdate.OldRoot)
+		for _, deployed := range pending.StateUpdate.StateDiff.DeployedContracts {
+			// classes that are already stored are not fetched again
+			if _, found := pending.Class(deployed.ClassHash); !found {
+				_, err := bc.GetClass(deployed.ClassHash)
+				assert.NoError(t, err)
+			}
+		}
+	})
+	t.Run("pending block is read again until its state update builds on the head", func(t *testing.T) {
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+		latest, err := gw.BlockLatest(context.Background())
+		require.NoError(t, err)
+		for number := uint64(0); number <= latest.Number; number++ {
+			block, err := gw.BlockByNumber(context.Background(), number)
+			require.NoError(t, err)
+			stateUpdate, err := gw.StateUpdate(context.Background(), number)
+			require.NoError(t, err)
+			require.NoError(t, bc.Store(block, stateUpdate, nil))
+		}
+
+		staleSource := &stalePendingSource{StarknetData: source, stale: 1}
+		require.NoError(t, NewSynchronizer(bc, staleSource, log).fetchPending(context.Background()))
+		assert.Equal(t, 2, staleSource.reads)
+		pending, err := bc.Pending()
+		require.NoError(t, err)
+		assert.Equal(t, latest.GlobalStateRoot, pending.StateUpdate.OldRoot)
+
+		t.Run("until it gives up", func(t *testing.T) {
+			staleSource := &stalePendingSource{StarknetData: source, stale: pendingFetchAttempts}
+			require.Error(t, NewSynchronizer(bc, staleSource, log).fetchPending(context.Background()))
+			assert.Equal(t, pendingFetchAttempts, staleSource.reads)
+		})
+	})
+	t.Run("stored blocks and pending blocks are published", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		synchronizer := NewSynchronizer(bc, source, log).WithPendingPollInterval(100 * time.Millisecond)
+		heads := synchronizer.SubscribeNewHeads()
+		defer heads.Unsubscribe()
+		pending := synchronizer.SubscribePending()
+		defer pending.Unsubscribe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error)
+		go func() {
+			runErr <- synchronizer.Run(ctx)
+		}()
+
+		// the pending block is only stored once the head it builds on is
+		var numbers []uint64
+		var pendingBlock *blockchain.Pending
+		timeout := time.After(30 * time.Second)
+		for pendingBlock == nil {
+			select {
+			case header := <-heads.C:
+				numbers = append(numbers, header.Number)
+			case pendingBlock = <-pending.C:
+			case <-timeout:
+				require.FailNow(t, "no pending block was published")
+			}
+		}
+		cancel()
+		require.NoError(t, <-runErr)
+		for len(heads.C) > 0 {
+			numbers = append(numbers, (<-heads.C).Number)
+		}
+
+		head, err := bc.Head()
+		require.NoError(t, err)
+		for i, number := range numbers {
+			assert.Equal(t, uint64(i), number)
+		}
+		assert.Len(t, numbers, int(head.Number)+1)
+		assert.Equal(t, head.Hash, pendingBlock.Block.ParentHash)
+
+		heads.Unsubscribe()
+		_, open := <-heads.C
+		assert.False(t, open)
+	})
+	t.Run("revert blocks of a stale fork", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		b0, err := gw.BlockByNumber(context.Background(), 0)
+		require.NoError(t, err)
+		s0, err := gw.StateUpdate(context.Background(), 0)
+		require.NoError(t, err)
+		require.NoError(t, bc.Store(b0, s0, nil))
+
+		// a block 1 that is not part of the canonical chain and leaves the state untouched
+		forkHash, err := new(felt.Felt).SetRandom()
+		require.NoError(t, err)
+		forkBlock := &core.Block{Header: core.Header{
+			Hash:            forkHash,
+			ParentHash:      b0.Hash,
+			Number:          1,
+			GlobalStateRoot: b0.GlobalStateRoot,
+		}}
+		forkStateUpdate := &core.StateUpdate{
+			BlockHash: forkHash,
+			NewRoot:   b0.GlobalStateRoot,
+			OldRoot:   b0.GlobalStateRoot,
+			StateDiff: new(core.StateDiff),
+		}
+		require.NoError(t, bc.Store(forkBlock, forkStateUpdate, nil))
+
+		synchronizer := NewSynchronizer(bc, source, log)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Second)
+			cancel()
+		}()
+		require.NoError(t, synchronizer.Run(ctx))
+
+		head, err := bc.Head()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), head.Number)
+		_, err = bc.GetBlockByHash(forkHash)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		testBlockchain(t, bc)
+	})
+	t.Run("sync stops if the head cannot be reverted", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		b0, err := gw.BlockByNumber(context.Background(), 0)
+		require.NoError(t, err)
+		s0, err := gw.StateUpdate(context.Background(), 0)
+		require.NoError(t, err)
+		require.NoError(t, bc.Store(b0, s0, nil))
+
+		forkHash, err := new(felt.Felt).SetRandom()
+		require.NoError(t, err)
+		forkBlock := &core.Block{Header: core.Header{
+			Hash:            forkHash,
+			ParentHash:      b0.Hash,
+			Number:          1,
+			GlobalStateRoot: b0.GlobalStateRoot,
+		}}
+		require.NoError(t, bc.Store(forkBlock, &core.StateUpdate{
+			BlockHash: forkHash,
+			NewRoot:   b0.GlobalStateRoot,
+			OldRoot:   b0.GlobalStateRoot,
+			StateDiff: new(core.StateDiff),
+		}, nil))
+		// without its state update the fork block cannot be reverted
+		require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+			return txn.Delete(db.StateUpdatesByBlockNumber.Key([]byte{0, 0, 0, 0, 0, 0, 0, 1}))
+		}))
+
+		synchronizer := NewSynchronizer(bc, source, log)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		err = synchronizer.Run(ctx)
+		var revertErr ErrRevertFailed
+		require.ErrorAs(t, err, &revertErr)
+		assert.Equal(t, uint64(1), revertErr.Height)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+
+		head, err := bc.Head()
+		require.NoError(t, err)
+		assert.Equal(t, forkHash, head.Hash)
+	})
+	t.Run("stored classes are not fetched again", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		b0, err := gw.BlockByNumber(context.Background(), 0)
+		require.NoError(t, err)
+		s0, err := gw.StateUpdate(context.Background(), 0)
+		require.NoError(t, err)
+		classes0 := make(map[felt.Felt]*core.Class)
+		for _, deployed := range s0.StateDiff.DeployedContracts {
+			class, err := source.Class(context.Background(), deployed.ClassHash)
+			require.NoError(t, err)
+			classes0[*deployed.ClassHash] = class
+		}
+		require.NotEmpty(t, classes0)
+		require.NoError(t, bc.Store(b0, s0, classes0))
+
+		counting := &classSource{StarknetData: source}
+		synchronizer := NewSynchronizer(bc, counting, log)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Second)
+			cancel()
+		}()
+		require.NoError(t, synchronizer.Run(ctx))
+
+		testBlockchain(t, bc)
+		for classHash := range classes0 {
+			assert.Zero(t, counting.fetches(&classHash), classHash.String())
+		}
+	})
+	t.Run("failed class fetches are retried", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		failing := &classSource{StarknetData: source, failures: 2}
+		synchronizer := NewSynchronizer(bc, failing, log)
+		synchronizer.fetchRetryInterval = 10 * time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Second)
+			cancel()
+		}()
+		require.NoError(t, synchronizer.Run(ctx))
+
+		testBlockchain(t, bc)
+		head, err := bc.Head()
+		require.NoError(t, err)
+		for number := uint64(0); number <= head.Number; number++ {
+			stateUpdate, err := bc.GetStateUpdateByNumber(number)
+			require.NoError(t, err)
+			for _, deployed := range stateUpdate.StateDiff.DeployedContracts {
+				_, err = bc.GetClass(deployed.ClassHash)
+				assert.NoError(t, err)
+			}
+		}
+	})
+	t.Run("blocks are not fetched beyond the lookahead window", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		window := &windowSource{StarknetData: source, bc: bc}
+		synchronizer := NewSynchronizer(bc, window, log).WithLookahead(1, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Second)
+			cancel()
+		}()
+		require.NoError(t, synchronizer.Run(ctx))
+
+		testBlockchain(t, bc)
+		assert.Zero(t, atomic.LoadUint64(&window.maxAhead))
+	})
+	t.Run("follow the chain tip", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		tip := &tipSource{StarknetData: source}
+		tip.setLatest(t, 0)
+
+		synchronizer := NewSynchronizer(bc, tip, log).WithHeadPollInterval(100 * time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
+		syncDone := make(chan struct{})
+		go func() {
+			require.NoError(t, synchronizer.Run(ctx))
+			close(syncDone)
+		}()
+
+		headIs := func(number uint64) func() bool {
+			return func() bool {
+				head, err := bc.Head()
+				return err == nil && head.Number == number
+			}
+		}
+		require.Eventually(t, headIs(0), 30*time.Second, 10*time.Millisecond)
+		starting, started := synchronizer.StartingBlockNumber()
+		assert.True(t, started)
+		assert.Equal(t, uint64(0), starting)
+
+		tip.setLatest(t, 2)
+		require.Eventually(t, headIs(2), 30*time.Second, 10*time.Millisecond)
+		cancel()
+		<-syncDone
+
+		head, err := bc.Head()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), head.Number)
+		assert.Equal(t, &head.Header, synchronizer.HighestBlockHeader())
+		testBlockchain(t, bc)
+	})
+}
+
+func TestClassFetcher(t *testing.T) {
+	gw, closeFn := testsource.NewTestGateway(utils.MAINNET)
+	defer closeFn()
+	stubbed := testsource.StubClasses(gw)
+	s0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	classHash := s0.StateDiff.DeployedContracts[0].ClassHash
+
+	source := &blockingClassSource{classSource: classSource{StarknetData: stubbed}, release: make(chan struct{})}
+	fetcher := newClassFetcher()
+
+	const callers = 5
+	classes := make(chan *core.Class, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			class, err := fetcher.fetch(context.Background(), source, classHash)
+			assert.NoError(t, err)
+			classes <- class
+		}()
+	}
+	// let all the callers join the fetch that is in flight
+	time.Sleep(100 * time.Millisecond)
+	close(source.release)
+
+	want, err := stubbed.Class(context.Background(), classHash)
+	require.NoError(t, err)
+	for i := 0; i < callers; i++ {
+		assert.Equal(t, want, <-classes)
+	}
+	assert.Equal(t, 1, source.fetches(classHash))
+
+	t.Run("waiting is cancelled with the context", func(t *testing.T) {
+		source := &blockingClassSource{classSource: classSource{StarknetData: stubbed}, release: make(chan struct{})}
+		go func() {
+			_, err := fetcher.fetch(context.Background(), source, classHash)
+			assert.NoError(t, err)
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := fetcher.fetch(ctx, source, classHash)
+		assert.ErrorIs(t, err, context.Canceled)
+		close(source.release)
+	})
+}
+
+func TestLookahead(t *testing.T) {
+	gw, closeFn := testsource.NewTestGateway(utils.MAINNET)
+	defer closeFn()
+	block, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	stateUpdate, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+
+	t.Run("no limit", func(t *testing.T) {
+		window := newLookahead(0, 0)
+		assert.Zero(t, window.add(block, stateUpdate, nil))
+		assert.False(t, window.full(1000, 0))
+	})
+	t.Run("blocks", func(t *testing.T) {
+		window := newLookahead(2, 0)
+		assert.False(t, window.full(5, 4))
+		assert.True(t, window.full(6, 4))
+		// blocks behind the stored head are fetched again after a rollback
+		assert.False(t, window.full(3, 4))
+	})
+	t.Run("bytes", func(t *testing.T) {
+		window := newLookahead(0, 1)
+		assert.False(t, window.full(0, 0))
+
+		size := window.add(block, stateUpdate, nil)
+		assert.NotZero(t, size)
+		assert.True(t, window.full(0, 0))
+
+		window.remove(size)
+		assert.False(t, window.full(0, 0))
+		select {
+		case <-window.freed:
+		default:
+			assert.Fail(t, "removing a block does not signal the freed room")
+		}
+	})
+}
+
+// tipSource is a StarknetData whose latest block can be moved by the test
+type tipSource struct {
+	starknetdata.StarknetData
+	latest atomic.Value // *core.Block
+}
+
+func (s *tipSource) setLatest(t *testing.T, number uint64) {
+	block, err := s.StarknetData.BlockByNumber(context.Background(), number)
+	require.NoError(t, err)
+	s.latest.Store(block)
+}
+
+func (s *tipSource) BlockLatest(ctx context.Context) (*core.Block, error) {
+	return s.latest.Load().(*core.Block), nil
+}
+
+// classSource is a StarknetData that counts the class fetches and fails the first ones
+type classSource struct {
+	starknetdata.StarknetData
+	failures int
+
+	lock  stdsync.Mutex
+	count map[felt.Felt]int
+}
+
+func (s *classSource) Class(ctx context.Context, classHash *felt.Felt) (*core.Class, error) {
+	s.lock.Lock()
+	if s.count == nil {
+		s.count = make(map[felt.Felt]int)
+	}
+	s.count[*classHash]++
+	fail := s.count[*classHash] <= s.failures
+	s.lock.Unlock()
+
+	if fail {
+		return nil, errors.New("class not available")
+	}
+	return s.StarknetData.Class(ctx, classHash)
+}
+
+func (s *classSource) fetches(classHash *felt.Felt) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.count[*classHash]
+}
+
+// blockingClassSource holds back class fetches until release is closed
+type blockingClassSource struct {
+	classSource
+	release chan struct{}
+}
+
+func (s *blockingClassSource) Class(ctx context.Context, classHash *felt.Felt) (*core.Class, error) {
+	<-s.release
+	return s.classSource.Class(ctx, classHash)
+}
+
+// windowSource records how far ahead of the stored head blocks are fetched
+type windowSource struct {
+	starknetdata.StarknetData
+	bc       *blockchain.Blockchain
+	maxAhead uint64
+}
+
+func (s *windowSource) BlockByNumber(ctx context.Context, number uint64) (*core.Block, error) {
+	nextToStore := uint64(0)
+	if height, err := s.bc.Height(); err == nil {
+		nextToStore = height + 1
+	}
+	if number > nextToStore {
+		for ahead := number - nextToStore; ; {
+			current := atomic.LoadUint64(&s.maxAhead)
+			if ahead <= current || atomic.CompareAndSwapUint64(&s.maxAhead, current, ahead) {
+				break
+			}
+		}
+	}
+	return s.StarknetData.BlockByNumber(ctx, number)
+}
+
+// stalePendingSource is a StarknetData whose first pending state updates belong to another
+// pending block than the one they are read with
+type stalePendingSource struct {
+	starknetdata.StarknetData
+	stale int
+	reads int
+}
+
+func (s *stalePendingSource) StateUpdatePendingWithBlock(ctx context.Context) (*core.StateUpdate, *core.Block, error) {
+	stateUpdate, block, err := s.StarknetData.StateUpdatePendingWithBlock(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.reads++
+	if s.reads <= s.stale {
+		stateUpdate.OldRoot = new(felt.Felt).SetUint64(1)
+	}
+	return stateUpdate, block, nil
+}
+
+type recordingListener struct {
+	stored uint64
+}
+
+func (l *recordingListener) OnBlockStored(number uint64) {
+	atomic.AddUint64(&l.stored, 1)
 }
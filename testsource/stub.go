@@ -0,0 +1,28 @@
+package testsource
+
+import (
+	"context"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/starknetdata"
+)
+
+// stubClasses serves a placeholder for the classes that are missing from the test data
+type stubClasses struct {
+	starknetdata.StarknetData
+}
+
+// StubClasses returns a StarknetData that serves an empty class for every class source cannot
+// provide. The test data only contains the classes that tests inspect, but syncing the test
+// blocks requires all the classes they reference.
+func StubClasses(source starknetdata.StarknetData) starknetdata.StarknetData {
+	return &stubClasses{StarknetData: source}
+}
+
+func (s *stubClasses) Class(ctx context.Context, classHash *felt.Felt) (*core.Class, error) {
+	if class, err := s.StarknetData.Class(ctx, classHash); err == nil {
+		return class, nil
+	}
+	return new(core.Class), nil
+}
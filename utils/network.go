@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 var ErrUnknownNetwork = errors.New("unknown network")
@@ -62,6 +63,37 @@ func (n Network) ChainId() *felt.Felt {
 	}
 }
 
+// CoreContractAddress returns the address of the Starknet core contract on L1
+func (n Network) CoreContractAddress() common.Address {
+	switch n {
+	case GOERLI:
+		return common.HexToAddress("0xde29d060D45901Fb19ED6C6e959EB22d8626708e")
+	case MAINNET:
+		return common.HexToAddress("0xc662c410C0ECf747543f5bA90660f6ABeBD9C8c4")
+	case GOERLI2:
+		return common.HexToAddress("0xa4eD3aD27c294565cB0DCc993BDdCC75432D498c")
+	case INTEGRATION:
+		return common.HexToAddress("0xd5c325D183C592C94998000C5e0EED9e6655c020")
+	default:
+		return common.Address{}
+	}
+}
+
+// CoreContractDeploymentBlock returns an L1 block at or before the one the Starknet core
+// contract was deployed in, none of its logs are older
+func (n Network) CoreContractDeploymentBlock() uint64 {
+	switch n {
+	case GOERLI, INTEGRATION:
+		return 4_000_000
+	case MAINNET:
+		return 13_500_000
+	case GOERLI2:
+		return 7_500_000
+	default:
+		return 0
+	}
+}
+
 func IsValidNetwork(n Network) bool {
 	return !(n.String() == "")
 }
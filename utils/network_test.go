@@ -5,6 +5,7 @@ import (
 
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,6 +60,31 @@ func TestNetwork(t *testing.T) {
 			}
 		}
 	})
+	t.Run("core contract address", func(t *testing.T) {
+		for _, n := range networks {
+			switch n {
+			case utils.GOERLI:
+				assert.Equal(t, common.HexToAddress("0xde29d060D45901Fb19ED6C6e959EB22d8626708e"), n.CoreContractAddress())
+			case utils.MAINNET:
+				assert.Equal(t, common.HexToAddress("0xc662c410C0ECf747543f5bA90660f6ABeBD9C8c4"), n.CoreContractAddress())
+			case utils.GOERLI2:
+				assert.Equal(t, common.HexToAddress("0xa4eD3aD27c294565cB0DCc993BDdCC75432D498c"), n.CoreContractAddress())
+			case utils.INTEGRATION:
+				assert.Equal(t, common.HexToAddress("0xd5c325D183C592C94998000C5e0EED9e6655c020"), n.CoreContractAddress())
+			default:
+				assert.Equal(t, common.Address{}, n.CoreContractAddress())
+			}
+		}
+	})
+	t.Run("core contract deployment block", func(t *testing.T) {
+		for _, n := range networks {
+			if utils.IsValidNetwork(n) {
+				assert.NotZero(t, n.CoreContractDeploymentBlock())
+			} else {
+				assert.Zero(t, n.CoreContractDeploymentBlock())
+			}
+		}
+	})
 }
 
 func TestValidNetwork(t *testing.T) {